@@ -1,78 +1,76 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"os"
 
 	"flag"
 
+	"github.com/fredrikkvalvik/temp-lang/pkg/diagnostic"
 	"github.com/fredrikkvalvik/temp-lang/pkg/evaluator"
 	"github.com/fredrikkvalvik/temp-lang/pkg/lexer"
 	"github.com/fredrikkvalvik/temp-lang/pkg/object"
 	"github.com/fredrikkvalvik/temp-lang/pkg/parser"
 	"github.com/fredrikkvalvik/temp-lang/pkg/repl"
 	"github.com/fredrikkvalvik/temp-lang/pkg/resolver"
+	"github.com/fredrikkvalvik/temp-lang/pkg/token"
 )
 
 func main() {
 	attach := flag.Bool("attach", false, "attach repl to a program after its execution")
+	maxDepth := flag.Int("max-depth", evaluator.DefaultMaxDepth, "the number of nested function calls before a stack overflow error")
 
 	flag.Parse()
+	interpreter := evaluator.NewInterpreter(os.Stdout)
+	interpreter.SetMaxDepth(*maxDepth)
+	files := token.NewFileSet()
 
 	if len(flag.Args()) > 0 {
 		path := flag.Arg(0)
-		file := readFile(path)
+		src := files.Add(path, readFile(path))
 		env := object.NewEnv(nil)
-		res, err := runProgram(file, env)
+		env.SetContext(interpreter)
+		res, errs := runProgram(src, env)
+		color := diagnostic.IsTerminal(os.Stdout)
 
-		if err != nil {
-			fmt.Println(err.Error())
+		if len(errs) > 0 {
+			diagnostic.RenderAll(os.Stdout, errs, color)
 			return
 		}
 
-		if res.Type() == object.OBJ_ERROR {
-			fmt.Println(res.Inspect())
+		if err, ok := res.(*object.ErrorObj); ok {
+			err.Diagnostic().Render(os.Stdout, color)
+			fmt.Print(err.StackTrace())
 			return
 		}
 
 		if attach != nil && *attach {
-			repl.New(os.Stdin, os.Stdout).Run(env)
+			repl.New(os.Stdin, os.Stdout, files).Run(env)
 		}
 
 	} else {
 		env := object.NewEnv(nil)
-		repl.New(os.Stdin, os.Stdout).Run(env)
+		env.SetContext(interpreter)
+		repl.New(os.Stdin, os.Stdout, files).Run(env)
 		return
 	}
 }
 
-func runProgram(in string, env *object.Environment) (object.Object, error) {
-
-	l := lexer.New(in)
-	if l.DidError() {
-		errs := ""
-		for _, err := range l.Errors() {
-			errs += fmt.Sprintf("%s\n", err)
-		}
-		return nil, errors.New(errs)
-	}
-
+// runs the program, or returns the diagnostics of the first stage that failed
+func runProgram(src *token.Source, env *object.Environment) (object.Object, []error) {
+	l := lexer.NewFromSource(src)
 	p := parser.New(l)
 	program := p.ParseProgram()
 
+	// the lexer is driven by the parser, so its errors are part of the parser errors
 	if p.DidError() {
-		errs := ""
-		for _, err := range p.Errors() {
-			errs += fmt.Sprintf("%s\n", err)
-		}
-		return nil, errors.New(errs)
+		return nil, p.Errors()
 	}
 
 	r := resolver.New(env)
 	r.Resolve(program)
 	if len(r.Errors) > 0 {
-		return nil, errors.Join(r.Errors...)
+		return nil, r.Errors
 	}
 
 	result := evaluator.Eval(program, env)
@@ -29,6 +29,48 @@ type Expr interface {
 	ExprNode() // Dummy method to make go treat Expr and Stmt differently
 }
 
+// Pattern is the left hand side of a match arm. A pattern is tested against a
+// value, and can bind parts of the value to new variables
+type Pattern interface {
+	Node
+	PatternNode() // Dummy method to make go treat Pattern differently from Expr and Stmt
+}
+
+// Parameter is a single parameter in a function literal.
+// Default is nil when the parameter has no default value.
+// A Rest parameter collects the remaining positional arguments in a list
+type Parameter struct {
+	Name    *IdentifierExpr
+	Default Expr
+	Rest    bool
+}
+
+func (p *Parameter) String() string {
+	switch {
+	case p.Rest:
+		return "..." + p.Name.String()
+	case p.Default != nil:
+		return fmt.Sprintf("%s = %s", p.Name.String(), p.Default.String())
+	default:
+		return p.Name.String()
+	}
+}
+
+// MatchArm is a single `pattern if guard => body` case in a match expression.
+// Guard is nil when the arm has no guard. Body is either a *BlockStmt or an *ExpressionStmt
+type MatchArm struct {
+	Pattern Pattern
+	Guard   Expr
+	Body    Stmt
+}
+
+// KeyValue is a single entry in a map literal.
+// For a spread entry `...expr`, Key is nil and Value is a *SpreadExpr
+type KeyValue struct {
+	Key   Expr
+	Value Expr
+}
+
 // program is the ast from one source file
 type Program struct {
 	Statements []Stmt
@@ -53,8 +95,17 @@ func (p *Program) String() string {
 func (l *LetStmt) String() string {
 	var s strings.Builder
 
+	keyword := "let"
+	if l.Const {
+		keyword = "const"
+	}
+
 	// TODO: update String when let is is fully implemented
-	fmt.Fprintf(&s, "let %s = %s;\n", l.Name.String(), l.Value.String())
+	if l.Pattern != nil {
+		fmt.Fprintf(&s, "%s %s = %s;\n", keyword, l.Pattern.String(), l.Value.String())
+	} else {
+		fmt.Fprintf(&s, "%s %s = %s;\n", keyword, l.Name.String(), l.Value.String())
+	}
 
 	return s.String()
 }
@@ -100,6 +151,39 @@ func (i *IfStmt) String() string {
 	return s.String()
 }
 
+func (n *IfExpr) String() string {
+	var s strings.Builder
+
+	fmt.Fprintf(&s, "if %s ", n.Condition.String())
+	fmt.Fprint(&s, n.Then.String())
+
+	if n.Else != nil {
+		fmt.Fprintf(&s, " else ")
+		fmt.Fprint(&s, n.Else.String())
+	}
+	return s.String()
+}
+
+func (n *RangeExpr) String() string {
+	var s strings.Builder
+
+	fmt.Fprintf(&s, "(%s%s%s", n.Start.String(), n.Lexeme(), n.End.String())
+	if n.Step != nil {
+		fmt.Fprintf(&s, " step %s", n.Step.String())
+	}
+	s.WriteString(")")
+
+	return s.String()
+}
+
+func (n *TernaryExpr) String() string {
+	return fmt.Sprintf("(%s ? %s : %s)", n.Condition.String(), n.Then.String(), n.Else.String())
+}
+
+func (n *PropagateExpr) String() string {
+	return fmt.Sprintf("(%s?)", n.Value.String())
+}
+
 func (b *BlockStmt) String() string {
 	var s strings.Builder
 
@@ -116,7 +200,7 @@ func (r *ReturnStmt) String() string {
 	str := "return"
 
 	if r.Value != nil {
-		str += " " + r.Token.String()
+		str += " " + r.Value.String()
 	}
 
 	return str
@@ -153,6 +237,41 @@ func (s *WhileStmt) String() string {
 	return str.String()
 }
 
+func (s *TryStmt) String() string {
+	var str strings.Builder
+
+	fmt.Fprintf(&str, "try %s", s.Body.String())
+
+	if s.Catch != nil {
+		fmt.Fprint(&str, " catch ")
+		if s.Param != nil {
+			fmt.Fprintf(&str, "(%s) ", s.Param.String())
+		}
+		fmt.Fprint(&str, s.Catch.String())
+	}
+
+	if s.Finally != nil {
+		fmt.Fprintf(&str, " finally %s", s.Finally.String())
+	}
+
+	return str.String()
+}
+
+func (s *ThrowStmt) String() string {
+	return fmt.Sprintf("throw %s", s.Value.String())
+}
+
+func (s *DeferStmt) String() string {
+	return fmt.Sprintf("defer %s", s.Value.String())
+}
+
+func (s *YieldStmt) String() string {
+	if s.Value == nil {
+		return "yield"
+	}
+	return fmt.Sprintf("yield %s", s.Value.String())
+}
+
 func (i *IdentifierExpr) String() string {
 	var str strings.Builder
 
@@ -207,7 +326,7 @@ func (p *FunctionLiteralExpr) String() string {
 
 	s.WriteString("fn(")
 	for idx, arg := range p.Arguments {
-		s.WriteString(arg.Value)
+		s.WriteString(arg.String())
 
 		if len(p.Arguments) != idx+1 {
 			s.WriteString(", ")
@@ -234,14 +353,38 @@ func (n *CallExpr) String() string {
 	return s.String()
 }
 
+func (n *NamedArgumentExpr) String() string {
+	return fmt.Sprintf("%s: %s", n.Name.String(), n.Value.String())
+}
+
 func (n *GetExpr) String() string {
+	if n.Optional {
+		return fmt.Sprintf("%s?.%s", n.Obj.String(), n.Name.String())
+	}
 	return fmt.Sprintf("%s.%s", n.Obj.String(), n.Name.String())
 }
 
 func (n *IndexExpr) String() string {
 	var str strings.Builder
 
-	fmt.Fprintf(&str, "%s[%s]", n.Left.String(), n.Index.String())
+	str.WriteString(n.Left.String())
+	if n.Optional {
+		str.WriteString("?")
+	}
+	str.WriteString("[")
+	if n.Index != nil {
+		str.WriteString(n.Index.String())
+	}
+	if n.Slice {
+		str.WriteString(":")
+		if n.End != nil {
+			str.WriteString(n.End.String())
+		}
+		if n.Step != nil {
+			fmt.Fprintf(&str, ":%s", n.Step.String())
+		}
+	}
+	str.WriteString("]")
 
 	return str.String()
 }
@@ -264,7 +407,43 @@ func (n *ListLiteralExpr) String() string {
 }
 
 func (n *MapLiteralExpr) String() string {
-	return "MAP LIT TODO"
+	var str strings.Builder
+
+	str.WriteString("{")
+	for idx, kv := range n.KeyValues {
+		if kv.Key == nil {
+			str.WriteString(kv.Value.String())
+		} else {
+			fmt.Fprintf(&str, "%s: %s", kv.Key.String(), kv.Value.String())
+		}
+
+		if idx != len(n.KeyValues)-1 {
+			str.WriteString(", ")
+		}
+	}
+	str.WriteString("}")
+
+	return str.String()
+}
+
+func (n *SpreadExpr) String() string {
+	return "..." + n.Value.String()
+}
+
+func (n *MatchExpr) String() string {
+	var str strings.Builder
+
+	fmt.Fprintf(&str, "match %s {\n", n.Subject.String())
+	for _, arm := range n.Arms {
+		str.WriteString(arm.Pattern.String())
+		if arm.Guard != nil {
+			fmt.Fprintf(&str, " if %s", arm.Guard.String())
+		}
+		fmt.Fprintf(&str, " => %s\n", strings.TrimSuffix(arm.Body.String(), "\n"))
+	}
+	str.WriteString("}")
+
+	return str.String()
 }
 
 func (n *NumberLiteralExpr) String() string {
@@ -279,6 +458,56 @@ func (s *BooleanLiteralExpr) String() string {
 	return s.Lexeme()
 }
 
+func (p *WildcardPattern) String() string {
+	return "_"
+}
+
+func (p *BindingPattern) String() string {
+	return p.Name.String()
+}
+
+func (p *LiteralPattern) String() string {
+	return p.Value.String()
+}
+
+func (p *ListPattern) String() string {
+	var str strings.Builder
+
+	str.WriteString("[")
+	for idx, elem := range p.Elements {
+		str.WriteString(elem.String())
+
+		if idx != len(p.Elements)-1 {
+			str.WriteString(", ")
+		}
+	}
+	if p.Rest != nil {
+		if len(p.Elements) > 0 {
+			str.WriteString(", ")
+		}
+		fmt.Fprintf(&str, "...%s", p.Rest.String())
+	}
+	str.WriteString("]")
+
+	return str.String()
+}
+
+func (p *MapPattern) String() string {
+	var str strings.Builder
+
+	str.WriteString("{")
+	for idx, key := range p.Keys {
+		fmt.Fprintf(&str, "%s: %s", key.String(), p.Values[idx].String())
+
+		if idx != len(p.Keys)-1 {
+			str.WriteString(", ")
+		}
+	}
+	str.WriteString("}")
+
+	return str.String()
+}
+
 // HELPERS
 
 // func indent(input string, indent int) string {
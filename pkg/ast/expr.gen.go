@@ -95,8 +95,10 @@ func (n *ParenExpr) GetToken() *token.Token { return &n.Token }
 
 type FunctionLiteralExpr struct {
 	Token     token.Token
-	Arguments []*IdentifierExpr
+	Name      string
+	Arguments []*Parameter
 	Body      *BlockStmt
+	Generator bool
 }
 
 func (n *FunctionLiteralExpr) ExprNode()              {}
@@ -107,16 +109,28 @@ type CallExpr struct {
 	Token     token.Token
 	Callee    Expr
 	Arguments []Expr
+	Tail      bool
 }
 
 func (n *CallExpr) ExprNode()              {}
 func (n *CallExpr) Lexeme() string         { return n.Token.Lexeme }
 func (n *CallExpr) GetToken() *token.Token { return &n.Token }
 
-type GetExpr struct {
+type NamedArgumentExpr struct {
 	Token token.Token
-	Obj   Expr
 	Name  *IdentifierExpr
+	Value Expr
+}
+
+func (n *NamedArgumentExpr) ExprNode()              {}
+func (n *NamedArgumentExpr) Lexeme() string         { return n.Token.Lexeme }
+func (n *NamedArgumentExpr) GetToken() *token.Token { return &n.Token }
+
+type GetExpr struct {
+	Token    token.Token
+	Obj      Expr
+	Name     *IdentifierExpr
+	Optional bool
 }
 
 func (n *GetExpr) ExprNode()              {}
@@ -134,23 +148,89 @@ func (n *ListLiteralExpr) GetToken() *token.Token { return &n.Token }
 
 type MapLiteralExpr struct {
 	Token     token.Token
-	KeyValues map[Expr]Expr
+	KeyValues []*KeyValue
 }
 
 func (n *MapLiteralExpr) ExprNode()              {}
 func (n *MapLiteralExpr) Lexeme() string         { return n.Token.Lexeme }
 func (n *MapLiteralExpr) GetToken() *token.Token { return &n.Token }
 
-type IndexExpr struct {
+type SpreadExpr struct {
 	Token token.Token
-	Left  Expr
-	Index Expr
+	Value Expr
+}
+
+func (n *SpreadExpr) ExprNode()              {}
+func (n *SpreadExpr) Lexeme() string         { return n.Token.Lexeme }
+func (n *SpreadExpr) GetToken() *token.Token { return &n.Token }
+
+type IndexExpr struct {
+	Token    token.Token
+	Left     Expr
+	Index    Expr
+	End      Expr
+	Step     Expr
+	Slice    bool
+	Optional bool
 }
 
 func (n *IndexExpr) ExprNode()              {}
 func (n *IndexExpr) Lexeme() string         { return n.Token.Lexeme }
 func (n *IndexExpr) GetToken() *token.Token { return &n.Token }
 
+type MatchExpr struct {
+	Token   token.Token
+	Subject Expr
+	Arms    []*MatchArm
+}
+
+func (n *MatchExpr) ExprNode()              {}
+func (n *MatchExpr) Lexeme() string         { return n.Token.Lexeme }
+func (n *MatchExpr) GetToken() *token.Token { return &n.Token }
+
+type IfExpr struct {
+	Token     token.Token
+	Condition Expr
+	Then      *BlockStmt
+	Else      Stmt
+}
+
+func (n *IfExpr) ExprNode()              {}
+func (n *IfExpr) Lexeme() string         { return n.Token.Lexeme }
+func (n *IfExpr) GetToken() *token.Token { return &n.Token }
+
+type RangeExpr struct {
+	Token     token.Token
+	Start     Expr
+	End       Expr
+	Step      Expr
+	Inclusive bool
+}
+
+func (n *RangeExpr) ExprNode()              {}
+func (n *RangeExpr) Lexeme() string         { return n.Token.Lexeme }
+func (n *RangeExpr) GetToken() *token.Token { return &n.Token }
+
+type PropagateExpr struct {
+	Token token.Token
+	Value Expr
+}
+
+func (n *PropagateExpr) ExprNode()              {}
+func (n *PropagateExpr) Lexeme() string         { return n.Token.Lexeme }
+func (n *PropagateExpr) GetToken() *token.Token { return &n.Token }
+
+type TernaryExpr struct {
+	Token     token.Token
+	Condition Expr
+	Then      Expr
+	Else      Expr
+}
+
+func (n *TernaryExpr) ExprNode()              {}
+func (n *TernaryExpr) Lexeme() string         { return n.Token.Lexeme }
+func (n *TernaryExpr) GetToken() *token.Token { return &n.Token }
+
 // this is gives us a compile time check to see of all the interafaces has ben properly implemented
 func _() {
 	_ = Expr(&IdentifierExpr{})
@@ -164,8 +244,15 @@ func _() {
 	_ = Expr(&ParenExpr{})
 	_ = Expr(&FunctionLiteralExpr{})
 	_ = Expr(&CallExpr{})
+	_ = Expr(&NamedArgumentExpr{})
 	_ = Expr(&GetExpr{})
 	_ = Expr(&ListLiteralExpr{})
 	_ = Expr(&MapLiteralExpr{})
+	_ = Expr(&SpreadExpr{})
 	_ = Expr(&IndexExpr{})
+	_ = Expr(&MatchExpr{})
+	_ = Expr(&IfExpr{})
+	_ = Expr(&RangeExpr{})
+	_ = Expr(&PropagateExpr{})
+	_ = Expr(&TernaryExpr{})
 }
@@ -10,9 +10,11 @@ import (
 
 const stmt = "Stmt"
 const expr = "Expr"
+const pattern = "Pattern"
 
 const stmtMethod = "func (%s *%s) StmtNode() {}"
 const exprMethod = "func (%s *%s) ExprNode() {}"
+const patternMethod = "func (%s *%s) PatternNode() {}"
 
 const packageName = "ast"
 const tokenPkg = "github.com/fredrikkvalvik/temp-lang/pkg/token"
@@ -31,7 +33,9 @@ var stmts = []template{
 		name: "Let",
 		props: []keyVal{
 			{"Name", "*Identifier" + expr},
+			{"Pattern", pattern}, // set instead of Name when the let destructures the value
 			{"Value", expr},
+			{"Const", "bool"}, // declared with `const`, and can't be reassigned
 		},
 	},
 	{
@@ -70,7 +74,7 @@ var stmts = []template{
 	{
 		name: "Iter",
 		props: []keyVal{
-			{"Name", expr},
+			{"Name", pattern},
 			{"Iterable", expr},
 			{"Body", "*Block" + stmt},
 		},
@@ -88,6 +92,33 @@ var stmts = []template{
 			{"Expressions", "[]" + expr},
 		},
 	},
+	{
+		name: "Try",
+		props: []keyVal{
+			{"Body", "*Block" + stmt},
+			{"Param", "*Identifier" + expr}, // nil when the catch clause has no binding
+			{"Catch", "*Block" + stmt},      // nil when there is no catch clause
+			{"Finally", "*Block" + stmt},    // nil when there is no finally clause
+		},
+	},
+	{
+		name: "Throw",
+		props: []keyVal{
+			{"Value", expr},
+		},
+	},
+	{
+		name: "Defer",
+		props: []keyVal{
+			{"Value", expr},
+		},
+	},
+	{
+		name: "Yield",
+		props: []keyVal{
+			{"Value", expr}, // nil when yielding nothing
+		},
+	},
 }
 
 var exprs = []template{
@@ -156,8 +187,10 @@ var exprs = []template{
 	{
 		name: "FunctionLiteral",
 		props: []keyVal{
-			{"Arguments", "[]*Identifier" + expr},
+			{"Name", "string"}, // empty for anonymous functions
+			{"Arguments", "[]*Parameter"},
 			{"Body", "*Block" + stmt},
+			{"Generator", "bool"}, // the body contains `yield`, and calling it returns an iterator
 		},
 	},
 	{
@@ -165,6 +198,14 @@ var exprs = []template{
 		props: []keyVal{
 			{"Callee", expr},
 			{"Arguments", "[]" + expr},
+			{"Tail", "bool"}, // set by the resolver when the call is returned directly from a function
+		},
+	},
+	{
+		name: "NamedArgument",
+		props: []keyVal{
+			{"Name", "*Identifier" + expr},
+			{"Value", expr},
 		},
 	},
 	{
@@ -172,6 +213,7 @@ var exprs = []template{
 		props: []keyVal{
 			{"Obj", expr},
 			{"Name", "*Identifier" + expr},
+			{"Optional", "bool"}, // `obj?.name` evaluates to nil when obj is nil
 		},
 	},
 	{
@@ -183,14 +225,95 @@ var exprs = []template{
 	{
 		name: "MapLiteral",
 		props: []keyVal{
-			{"KeyValues", fmt.Sprintf("map[%s]%s", expr, expr)},
+			{"KeyValues", "[]*KeyValue"},
+		},
+	},
+	{
+		name: "Spread",
+		props: []keyVal{
+			{"Value", expr},
 		},
 	},
 	{
 		name: "Index",
 		props: []keyVal{
 			{"Left", expr},
-			{"Index", expr},
+			{"Index", expr}, // the start of a slice, nil when omitted
+			{"End", expr},   // nil when omitted
+			{"Step", expr},  // nil when omitted
+			{"Slice", "bool"},
+			{"Optional", "bool"}, // `left?[index]` evaluates to nil when left is nil
+		},
+	},
+	{
+		name: "Match",
+		props: []keyVal{
+			{"Subject", expr},
+			{"Arms", "[]*MatchArm"},
+		},
+	},
+	{
+		name: "If",
+		props: []keyVal{
+			{"Condition", expr},
+			{"Then", "*Block" + stmt},
+			{"Else", stmt}, // *BlockStmt, or an *ExpressionStmt holding an `else if`
+		},
+	},
+	{
+		name: "Range",
+		props: []keyVal{
+			{"Start", expr},
+			{"End", expr},
+			{"Step", expr}, // nil when omitted
+			{"Inclusive", "bool"},
+		},
+	},
+	{
+		name: "Propagate",
+		props: []keyVal{
+			{"Value", expr},
+		},
+	},
+	{
+		name: "Ternary",
+		props: []keyVal{
+			{"Condition", expr},
+			{"Then", expr},
+			{"Else", expr},
+		},
+	},
+}
+
+var patterns = []template{
+	{
+		name:  "Wildcard",
+		props: []keyVal{},
+	},
+	{
+		name: "Binding",
+		props: []keyVal{
+			{"Name", "*Identifier" + expr},
+		},
+	},
+	{
+		name: "Literal",
+		props: []keyVal{
+			{"Value", expr},
+		},
+	},
+	{
+		name: "List",
+		props: []keyVal{
+			{"Elements", "[]" + pattern},
+			{"Rest", pattern}, // nil when the pattern has no `...rest` element
+		},
+	},
+	{
+		name: "Map",
+		props: []keyVal{
+			{"Keys", "[]" + expr},
+			{"Values", "[]" + pattern},
 		},
 	},
 }
@@ -200,9 +323,11 @@ var exprs = []template{
 func main() {
 	statementsFile := generateNodes(stmt, stmtMethod, stmts)
 	expressionFile := generateNodes(expr, exprMethod, exprs)
+	patternFile := generateNodes(pattern, patternMethod, patterns)
 
 	os.WriteFile("stmt.gen.go", []byte(statementsFile), 0646)
 	os.WriteFile("expr.gen.go", []byte(expressionFile), 0646)
+	os.WriteFile("pattern.gen.go", []byte(patternFile), 0646)
 }
 
 func generateNodes(interfaceName, interfaceMethod string, tmpl []template) string {
@@ -0,0 +1,60 @@
+// THIS FILE IS GENERATED. DO NOT EDIT
+
+package ast
+
+import "github.com/fredrikkvalvik/temp-lang/pkg/token"
+
+type WildcardPattern struct {
+	Token token.Token
+}
+
+func (n *WildcardPattern) PatternNode()           {}
+func (n *WildcardPattern) Lexeme() string         { return n.Token.Lexeme }
+func (n *WildcardPattern) GetToken() *token.Token { return &n.Token }
+
+type BindingPattern struct {
+	Token token.Token
+	Name  *IdentifierExpr
+}
+
+func (n *BindingPattern) PatternNode()           {}
+func (n *BindingPattern) Lexeme() string         { return n.Token.Lexeme }
+func (n *BindingPattern) GetToken() *token.Token { return &n.Token }
+
+type LiteralPattern struct {
+	Token token.Token
+	Value Expr
+}
+
+func (n *LiteralPattern) PatternNode()           {}
+func (n *LiteralPattern) Lexeme() string         { return n.Token.Lexeme }
+func (n *LiteralPattern) GetToken() *token.Token { return &n.Token }
+
+type ListPattern struct {
+	Token    token.Token
+	Elements []Pattern
+	Rest     Pattern
+}
+
+func (n *ListPattern) PatternNode()           {}
+func (n *ListPattern) Lexeme() string         { return n.Token.Lexeme }
+func (n *ListPattern) GetToken() *token.Token { return &n.Token }
+
+type MapPattern struct {
+	Token  token.Token
+	Keys   []Expr
+	Values []Pattern
+}
+
+func (n *MapPattern) PatternNode()           {}
+func (n *MapPattern) Lexeme() string         { return n.Token.Lexeme }
+func (n *MapPattern) GetToken() *token.Token { return &n.Token }
+
+// this is gives us a compile time check to see of all the interafaces has ben properly implemented
+func _() {
+	_ = Pattern(&WildcardPattern{})
+	_ = Pattern(&BindingPattern{})
+	_ = Pattern(&LiteralPattern{})
+	_ = Pattern(&ListPattern{})
+	_ = Pattern(&MapPattern{})
+}
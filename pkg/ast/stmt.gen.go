@@ -5,9 +5,11 @@ package ast
 import "github.com/fredrikkvalvik/temp-lang/pkg/token"
 
 type LetStmt struct {
-	Token token.Token
-	Name  *IdentifierExpr
-	Value Expr
+	Token   token.Token
+	Name    *IdentifierExpr
+	Pattern Pattern
+	Value   Expr
+	Const   bool
 }
 
 func (n *LetStmt) StmtNode()              {}
@@ -64,7 +66,7 @@ func (n *ReturnStmt) GetToken() *token.Token { return &n.Token }
 
 type IterStmt struct {
 	Token    token.Token
-	Name     Expr
+	Name     Pattern
 	Iterable Expr
 	Body     *BlockStmt
 }
@@ -92,6 +94,45 @@ func (n *PrintStmt) StmtNode()              {}
 func (n *PrintStmt) Lexeme() string         { return n.Token.Lexeme }
 func (n *PrintStmt) GetToken() *token.Token { return &n.Token }
 
+type TryStmt struct {
+	Token   token.Token
+	Body    *BlockStmt
+	Param   *IdentifierExpr
+	Catch   *BlockStmt
+	Finally *BlockStmt
+}
+
+func (n *TryStmt) StmtNode()              {}
+func (n *TryStmt) Lexeme() string         { return n.Token.Lexeme }
+func (n *TryStmt) GetToken() *token.Token { return &n.Token }
+
+type ThrowStmt struct {
+	Token token.Token
+	Value Expr
+}
+
+func (n *ThrowStmt) StmtNode()              {}
+func (n *ThrowStmt) Lexeme() string         { return n.Token.Lexeme }
+func (n *ThrowStmt) GetToken() *token.Token { return &n.Token }
+
+type DeferStmt struct {
+	Token token.Token
+	Value Expr
+}
+
+func (n *DeferStmt) StmtNode()              {}
+func (n *DeferStmt) Lexeme() string         { return n.Token.Lexeme }
+func (n *DeferStmt) GetToken() *token.Token { return &n.Token }
+
+type YieldStmt struct {
+	Token token.Token
+	Value Expr
+}
+
+func (n *YieldStmt) StmtNode()              {}
+func (n *YieldStmt) Lexeme() string         { return n.Token.Lexeme }
+func (n *YieldStmt) GetToken() *token.Token { return &n.Token }
+
 // this is gives us a compile time check to see of all the interafaces has ben properly implemented
 func _() {
 	_ = Stmt(&LetStmt{})
@@ -103,4 +144,8 @@ func _() {
 	_ = Stmt(&IterStmt{})
 	_ = Stmt(&WhileStmt{})
 	_ = Stmt(&PrintStmt{})
+	_ = Stmt(&TryStmt{})
+	_ = Stmt(&ThrowStmt{})
+	_ = Stmt(&DeferStmt{})
+	_ = Stmt(&YieldStmt{})
 }
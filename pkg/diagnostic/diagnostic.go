@@ -0,0 +1,174 @@
+// diagnostic is the shared error type of the lexer, parser, resolver and
+// evaluator. A diagnostic points to a span of the source, and can be rendered
+// with the source line it comes from and the span underlined:
+//
+//	error: Can't assign to a constant
+//	 --> 2:1
+//	  |
+//	2 | a = 2
+//	  | ^
+//	  = hint: declare `a` with `let` to make it assignable
+package diagnostic
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fredrikkvalvik/temp-lang/pkg/token"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// Diagnostic is an error at a span of the source
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+	Span     token.Pos
+	Hints    []string
+
+	// the error the diagnostic was made from, so errors.Is can look for sentinels
+	err error
+}
+
+// New returns an error diagnostic for err at span
+func New(span token.Pos, err error, hints ...string) *Diagnostic {
+	return &Diagnostic{
+		Severity: Error,
+		Message:  err.Error(),
+		Span:     span,
+		Hints:    hints,
+		err:      err,
+	}
+}
+
+// Errorf returns an error diagnostic at span, formatted like fmt.Errorf
+func Errorf(span token.Pos, format string, args ...any) *Diagnostic {
+	return New(span, fmt.Errorf(format, args...))
+}
+
+// Error returns the message prefixed with the position, [line:col] message
+func (d *Diagnostic) Error() string {
+	if d.Span.Src == nil {
+		return d.Message
+	}
+	return fmt.Sprintf("%s %s", d.Span, d.Message)
+}
+
+func (d *Diagnostic) Unwrap() error { return d.err }
+
+// WithHint adds a hint shown below the source line
+func (d *Diagnostic) WithHint(format string, args ...any) *Diagnostic {
+	d.Hints = append(d.Hints, fmt.Sprintf(format, args...))
+	return d
+}
+
+const (
+	colorReset  = "\033[0m"
+	colorBold   = "\033[1m"
+	colorRed    = "\033[1;31m"
+	colorYellow = "\033[1;33m"
+	colorBlue   = "\033[1;34m"
+)
+
+// Render writes the diagnostic with the source line and the span underlined.
+// Colors are ANSI escape codes, and should only be used when w is a terminal
+func (d *Diagnostic) Render(w io.Writer, color bool) {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + colorReset
+	}
+	severityColor := colorRed
+	if d.Severity == Warning {
+		severityColor = colorYellow
+	}
+
+	// the message can span multiple lines, only the first is the headline
+	headline, rest, _ := strings.Cut(d.Message, "\n")
+	fmt.Fprintf(w, "%s%s\n", paint(severityColor, d.Severity.String()), paint(colorBold, ": "+headline))
+	if rest != "" {
+		fmt.Fprintln(w, rest)
+	}
+
+	if d.Span.Src == nil {
+		for _, hint := range d.Hints {
+			fmt.Fprintf(w, "  = hint: %s\n", hint)
+		}
+		return
+	}
+
+	line, _ := d.Span.Position()
+	src, start := d.Span.Src.Line(line), d.Span.Src.LineStart(line)
+	gutter := strings.Repeat(" ", len(fmt.Sprint(line)))
+
+	fmt.Fprintf(w, "%s %s\n", paint(colorBlue, gutter+"-->"), d.Span.Location())
+	fmt.Fprintf(w, "%s\n", paint(colorBlue, gutter+" |"))
+	fmt.Fprintf(w, "%s %s\n", paint(colorBlue, fmt.Sprintf("%d |", line)), src)
+	fmt.Fprintf(w, "%s %s\n", paint(colorBlue, gutter+" |"), paint(severityColor, underline(src, d.Span.Start-start, d.Span.End-start)))
+	for _, hint := range d.Hints {
+		fmt.Fprintf(w, "%s %s\n", paint(colorBlue, gutter+" ="), "hint: "+hint)
+	}
+}
+
+// returns the carets under line[from:to]. Tabs before the span are kept so the
+// carets line up with the source line, and the span is cut off at the end of the line
+func underline(line string, from, to int) string {
+	from = max(0, min(from, len(line)))
+	to = max(from, min(to, len(line)))
+
+	var str strings.Builder
+	for _, ch := range line[:from] {
+		if ch == '\t' {
+			str.WriteRune('\t')
+		} else {
+			str.WriteRune(' ')
+		}
+	}
+	// an empty span, like the end of the file, still gets a caret
+	str.WriteString(strings.Repeat("^", max(1, utf8.RuneCountInString(line[from:to]))))
+	return str.String()
+}
+
+// RenderAll renders every error to w. Errors that are not diagnostics
+// are written as a message without source
+func RenderAll(w io.Writer, errs []error, color bool) {
+	for _, err := range errs {
+		var d *Diagnostic
+		if !errors.As(err, &d) {
+			d = &Diagnostic{Severity: Error, Message: err.Error(), err: err}
+		}
+		d.Render(w, color)
+	}
+}
+
+// IsTerminal reports if w is a terminal, where colors can be used
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
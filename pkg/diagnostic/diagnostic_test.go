@@ -0,0 +1,61 @@
+package diagnostic
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fredrikkvalvik/temp-lang/pkg/tester"
+	"github.com/fredrikkvalvik/temp-lang/pkg/token"
+)
+
+var errTest = errors.New("Test error")
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		src        string
+		start, end int
+		hints      []string
+		expected   string
+	}{
+		{"let a = 1\na = 2", 10, 11, nil,
+			"error: Test error\n --> 2:1\n  |\n2 | a = 2\n  | ^\n"},
+		{"let word = 1", 4, 8, []string{"a hint"},
+			"error: Test error\n --> 1:5\n  |\n1 | let word = 1\n  |     ^^^^\n  = hint: a hint\n"},
+		// tabs are kept, and multi byte characters get one caret
+		{"\tx = \"é\" + 1", 5, 9, nil,
+			"error: Test error\n --> 1:6\n  |\n1 | \tx = \"é\" + 1\n  | \t    ^^^\n"},
+		// the end of the file gets a caret
+		{"let a =", 7, 7, nil,
+			"error: Test error\n --> 1:8\n  |\n1 | let a =\n  |        ^\n"},
+		{strings.Repeat("\n", 9) + "a", 9, 10, nil,
+			"error: Test error\n  --> 10:1\n   |\n10 | a\n   | ^\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			d := New(token.Pos{Src: token.NewSource("", tt.src), Start: tt.start, End: tt.end}, errTest, tt.hints...)
+			var out bytes.Buffer
+			d.Render(&out, false)
+
+			tr.AssertEqual(out.String(), tt.expected)
+		})
+	}
+}
+
+func TestDiagnosticError(t *testing.T) {
+	tr := tester.New(t, "")
+	src := "let a = 1\na = 2"
+
+	d := Errorf(token.Pos{Src: token.NewSource("", src), Start: 10, End: 11}, "%w `a`", errTest)
+	tr.AssertEqual(d.Error(), "[2:1] Test error `a`")
+	tr.AssertTrue(errors.Is(d, errTest))
+
+	// errors that are not diagnostics are rendered without source
+	var out bytes.Buffer
+	RenderAll(&out, []error{errTest, d}, false)
+	tr.AssertTrue(strings.HasPrefix(out.String(), "error: Test error\nerror: Test error `a`\n"), out.String())
+}
@@ -8,5 +8,26 @@ var builtins = map[string]*object.BuiltinObj{
 	"pop":   {Name: "pop", Fn: object.PopBuiltin},
 	"str":   {Name: "str", Fn: object.StrBuiltin},
 	"range": {Name: "range", Fn: object.RangeBuiltin},
-	"iter":  {Name: "iter", Fn: object.IterBuiltin},
+	"iter":  {Name: "iter", ContextFn: object.IterBuiltin},
+
+	"freeze": {Name: "freeze", Fn: object.FreezeBuiltin},
+
+	"error":    {Name: "error", Fn: object.ErrorBuiltin},
+	"is_error": {Name: "is_error", Fn: isErrorBuiltin},
+
+	"sort":   {Name: "sort", ContextFn: object.SortBuiltin},
+	"map":    {Name: "map", ContextFn: object.MapBuiltin},
+	"filter": {Name: "filter", ContextFn: object.FilterBuiltin},
+}
+
+// Arity: 1
+//
+// Arg0: any
+//
+// is_error reports if the argument is an error value
+func isErrorBuiltin(args ...object.Object) object.Object {
+	if err := object.CheckArity(args, 1); err != nil {
+		return err
+	}
+	return boolObject(args[0].Type() == object.OBJ_ERROR_VALUE)
 }
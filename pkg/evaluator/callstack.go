@@ -0,0 +1,60 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/fredrikkvalvik/temp-lang/pkg/object"
+	"github.com/fredrikkvalvik/temp-lang/pkg/token"
+)
+
+// DefaultMaxDepth is the number of nested calls that can be made before a
+// StackOverflowError is returned. Calls in tail position replace the current
+// call, and don't count
+const DefaultMaxDepth = 10_000
+
+// SetMaxDepth changes the number of nested calls that can be made
+func (in *Interpreter) SetMaxDepth(depth int) {
+	in.maxDepth = depth
+}
+
+// enters a call, or returns an error when the max depth is reached
+func (in *Interpreter) pushCall(tok *token.Token) *object.ErrorObj {
+	if in.depth >= in.maxDepth {
+		return in.stackOverflowError(tok)
+	}
+	in.depth++
+	return nil
+}
+
+func (in *Interpreter) popCall() {
+	in.depth--
+}
+
+// adds the call to the trace of an error that unwinds through it
+func traceCall(err *object.ErrorObj, callee object.Object, tok *token.Token) *object.ErrorObj {
+	err.Trace = append(err.Trace, object.TraceFrame{Name: calleeName(callee), Token: tok})
+	if tok != nil {
+		return enrichError(err, &EnrichErrorParams{tok})
+	}
+	return err
+}
+
+// the calls that overflowed the stack are added to the trace of the error as it unwinds
+func (in *Interpreter) stackOverflowError(tok *token.Token) *object.ErrorObj {
+	err := newError(StackOverflowError, fmt.Sprintf("max call depth of %d exceeded", in.maxDepth))
+	return enrichError(err, &EnrichErrorParams{tok})
+}
+
+func calleeName(callee object.Object) string {
+	switch fn := callee.(type) {
+	case *object.FnLiteralObj:
+		if fn.Name != "" {
+			return fn.Name
+		}
+		return "<anonymous>"
+	case *object.BuiltinObj:
+		return fn.Name
+	default:
+		return callee.Inspect()
+	}
+}
@@ -0,0 +1,51 @@
+package evaluator
+
+import (
+	"io"
+	"os"
+
+	"github.com/fredrikkvalvik/temp-lang/pkg/object"
+)
+
+// Interpreter holds the state of one evaluation, and is the context passed to
+// builtins with a ContextFn. It is set on the global scope of a program with
+// env.SetContext, and every scope inside it uses the same interpreter
+type Interpreter struct {
+	// the writer that print, and builtins that print, write to
+	out io.Writer
+
+	maxDepth int
+	// the number of calls that have not returned yet
+	depth int
+}
+
+// NewInterpreter returns an interpreter that prints to out
+func NewInterpreter(out io.Writer) *Interpreter {
+	return &Interpreter{out: out, maxDepth: DefaultMaxDepth}
+}
+
+// returns the interpreter evaluating env. A program that is evaluated
+// without one gets an interpreter that prints to stdout
+func interpreter(env *object.Environment) *Interpreter {
+	in, ok := env.Context().(*Interpreter)
+	if !ok {
+		in = NewInterpreter(os.Stdout)
+		env.SetContext(in)
+	}
+	return in
+}
+
+func (in *Interpreter) Call(fn object.Object, args ...object.Object) object.Object {
+	// builtins don't have a call site
+	if err := in.pushCall(nil); err != nil {
+		return err
+	}
+	res := applyFunction(in, fn, args, nil)
+	in.popCall()
+	if isError(res) {
+		return traceCall(res.(*object.ErrorObj), fn, nil)
+	}
+	return res
+}
+
+func (in *Interpreter) Out() io.Writer { return in.out }
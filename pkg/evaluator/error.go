@@ -3,6 +3,7 @@ package evaluator
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/fredrikkvalvik/temp-lang/pkg/ast"
 	"github.com/fredrikkvalvik/temp-lang/pkg/object"
@@ -24,10 +25,47 @@ var (
 	IllegalIndexError        RuntimeError = errors.New("Illegal Index type")
 	IndexOutOfBoundsError    RuntimeError = errors.New("Index out of bound")
 
+	MatchError       RuntimeError = errors.New("Non-exhaustive match")
+	DestructureError RuntimeError = errors.New("Value can't be destructured")
+	SpreadError      RuntimeError = errors.New("Value can't be spread")
+
+	// too many nested calls, see Interpreter.SetMaxDepth
+	StackOverflowError RuntimeError = errors.New("Stack overflow")
+
+	// a value passed to `throw` that was not caught
+	ThrownError RuntimeError = errors.New("Uncaught error")
+	// unwinds to the closest function call when `value?` sees an error value.
+	// the function then returns the error value
+	PropagatedError RuntimeError = errors.New("Error value propagated outside function")
+
 	// Internal error only
 	UnknownNodeError RuntimeError = errors.New("Unknown node")
 )
 
+// the kind a caught error gets in a catch block. Errors that don't match
+// any of these get the kind "Error"
+var errorKinds = []struct {
+	err  error
+	kind string
+}{
+	{TypeError, "TypeError"},
+	{UseOfUndeclaredError, "UseOfUndeclaredError"},
+	{IllegalOperationError, "IllegalOperationError"},
+	{IllegalGlobalReturnError, "IllegalGlobalReturnError"},
+	{IllegalRedaclarationError, "IllegalRedaclarationError"},
+	{IllegalAssignmentError, "IllegalAssignmentError"},
+	{IllegalFloatAsIndexError, "IllegalFloatAsIndexError"},
+	{IllegalIndexError, "IllegalIndexError"},
+	{IndexOutOfBoundsError, "IndexOutOfBoundsError"},
+	{MatchError, "MatchError"},
+	{DestructureError, "DestructureError"},
+	{SpreadError, "SpreadError"},
+	{StackOverflowError, "StackOverflow"},
+	{object.ArityError, "ArityError"},
+	{object.TypeError, "TypeError"},
+	{object.FrozenError, "FrozenError"},
+}
+
 // TODO: add line:col numbers to errors
 
 func isError(obj object.Object) bool {
@@ -45,6 +83,66 @@ func newError(err RuntimeError, msgs ...string) *object.ErrorObj {
 	return &object.ErrorObj{Error: errors.Join(errs...)}
 }
 
+// internal errors are bugs in the interpreter, and can't be caught by scripts.
+// a propagated error value is a return, and is not caught either
+func isCatchable(err *object.ErrorObj) bool {
+	return !errors.Is(err.Error, UnknownNodeError) && !errors.Is(err.Error, PropagatedError)
+}
+
+// converts an error to the value that is bound in a catch block
+func errorValue(err *object.ErrorObj) *object.ErrorValueObj {
+	if err.Value != nil {
+		// rethrowing a caught error keeps its kind and position
+		if caught, ok := err.Value.(*object.ErrorValueObj); ok {
+			return caught
+		}
+		return &object.ErrorValueObj{
+			Kind:    "Error",
+			Message: thrownMessage(err.Value),
+			Value:   err.Value,
+			Token:   err.Token,
+		}
+	}
+
+	kind := "Error"
+	for _, k := range errorKinds {
+		if errors.Is(err.Error, k.err) {
+			kind = k.kind
+			break
+		}
+	}
+
+	return &object.ErrorValueObj{
+		Kind:    kind,
+		Message: strings.ReplaceAll(err.Error.Error(), "\n", ": "),
+		Token:   err.Token,
+	}
+}
+
+// creates the error that unwinds the stack when value is thrown
+func throwError(value object.Object, tok *token.Token) *object.ErrorObj {
+	message := thrownMessage(value)
+	if caught, ok := value.(*object.ErrorValueObj); ok {
+		message = caught.Kind + ": " + caught.Message
+		if caught.Token != nil {
+			tok = caught.Token
+		}
+	}
+
+	err := newError(ThrownError, message)
+	err.Token = tok
+	err.Value = value
+	return err
+}
+
+// strings are thrown as is, every other value is inspected
+func thrownMessage(value object.Object) string {
+	if str, ok := value.(*object.StringObj); ok {
+		return str.Value
+	}
+	return value.Inspect()
+}
+
 type EnrichErrorParams struct {
 	Tok *token.Token
 }
@@ -1,8 +1,13 @@
 package evaluator
 
 import (
+	"errors"
 	"fmt"
+	"iter"
+	"maps"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/fredrikkvalvik/temp-lang/pkg/ast"
 	"github.com/fredrikkvalvik/temp-lang/pkg/object"
@@ -24,8 +29,15 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.Program:
 		return evalProgram(n.Statements, env)
 	case *ast.LetStmt:
-		key := n.Name.Value
 		value := Eval(n.Value, env)
+		// a return inside an if or match expression exits the function
+		if isError(value) || value.Type() == object.OBJ_RETURN {
+			return value
+		}
+		if n.Pattern != nil {
+			return evalDestructure(n.Pattern, value, env)
+		}
+		key := n.Name.Value
 		return env.DeclareVar(key, value)
 
 	case *ast.ImportStmt:
@@ -67,6 +79,42 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.WhileStmt:
 		return evalWhileStatement(n, env)
 
+	case *ast.TryStmt:
+		return evalTryStatement(n, env)
+
+	case *ast.ThrowStmt:
+		value := Eval(n.Value, env)
+		if isError(value) {
+			return value
+		}
+		return throwError(value, n.Value.GetToken())
+
+	case *ast.DeferStmt:
+		frame := env.Frame()
+		if frame == nil {
+			return enrichError(newError(IllegalOperationError, "can't defer outside function body"), &EnrichErrorParams{n.GetToken()})
+		}
+		frame.Defer(n.Value, env)
+		return NIL
+
+	case *ast.YieldStmt:
+		frame := env.Frame()
+		if frame == nil || frame.Yield == nil {
+			return enrichError(newError(IllegalOperationError, "can't yield outside function body"), &EnrichErrorParams{n.GetToken()})
+		}
+		var value object.Object = NIL
+		if n.Value != nil {
+			value = Eval(n.Value, env)
+			if isError(value) {
+				return value
+			}
+		}
+		if !frame.Yield(value) {
+			// the consumer has stopped, so the generator returns
+			return &object.ReturnObj{Value: NIL}
+		}
+		return NIL
+
 	case *ast.UnaryExpr:
 		right := Eval(n.Right, env)
 		if isError(right) {
@@ -83,34 +131,73 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(right) {
 			return right
 		}
-		return evalBinaryExpression(left, right, n.Operand)
+		res := evalBinaryExpression(left, right, n.Operand)
+		if isError(res) {
+			return enrichError(res.(*object.ErrorObj), &EnrichErrorParams{&n.Token})
+		}
+		return res
 
 	case *ast.LogicalExpr:
-		left := Eval(n.Left, env)
+		if n.Operand == token.NULLISH {
+			left := Eval(n.Left, env)
+			if isError(left) || left != NIL {
+				return left
+			}
+			return Eval(n.Right, env)
+		}
+
+		left := evalCondition(n.Left, env)
 		if isError(left) {
 			return left
 		}
-		if left.Type() != object.OBJ_BOOL {
-			err := newError(TypeError, left.Inspect()+" is not of type: boolean")
-			return enrichError(err, &EnrichErrorParams{n.Left.GetToken()})
-		}
 
 		if (n.Operand == token.AND && left == TRUE) ||
 			(n.Operand == token.OR && left == FALSE) {
-			right := Eval(n.Right, env)
-			if isError(right) {
-				return right
-			}
-
-			if right.Type() != object.OBJ_BOOL {
-				err := newError(TypeError, right.Inspect()+" is not of type: boolean")
-				return enrichError(err, &EnrichErrorParams{n.Right.GetToken()})
-			}
-			return right
+			return evalCondition(n.Right, env)
 		}
 
 		return left
 
+	case *ast.IfExpr:
+		condition := evalCondition(n.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if condition == TRUE {
+			return Eval(n.Then, env)
+		} else if n.Else != nil {
+			return Eval(n.Else, env)
+		}
+		return NIL
+
+	case *ast.RangeExpr:
+		res := evalRangeExpression(n, env)
+		if isError(res) {
+			return enrichError(res.(*object.ErrorObj), &EnrichErrorParams{n.GetToken()})
+		}
+		return res
+
+	case *ast.PropagateExpr:
+		value := Eval(n.Value, env)
+		if isError(value) || value.Type() != object.OBJ_ERROR_VALUE {
+			return value
+		}
+		// unwinds like a runtime error, and is turned into the return value in applyFunction
+		err := newError(PropagatedError)
+		err.Token = n.GetToken()
+		err.Value = value
+		return err
+
+	case *ast.TernaryExpr:
+		condition := evalCondition(n.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if condition == TRUE {
+			return Eval(n.Then, env)
+		}
+		return Eval(n.Else, env)
+
 	case *ast.AssignExpr:
 		return evalAssignment(n, env)
 
@@ -135,91 +222,27 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	case *ast.FunctionLiteralExpr:
 		fn := &object.FnLiteralObj{
+			Name:       n.Name,
 			Parameters: n.Arguments,
 			Body:       n.Body,
 			Env:        env,
+			Generator:  n.Generator,
 		}
 		return fn
 
-	case *ast.CallExpr:
-		callee := Eval(n.Callee, env)
-
-		args := evalExpressions(n.Arguments, env)
-		if len(args) > 0 && isError(args[0]) {
-			return args[0]
-		}
-
-		res := applyFunction(callee, args)
-		if isError(res) {
-			return enrichError(res.(*object.ErrorObj), &EnrichErrorParams{&n.Token})
-		}
-		return res
-
 	case *ast.ParenExpr:
 		return Eval(n.Expression, env)
 
-	case *ast.IndexExpr:
-		left := Eval(n.Left, env)
-		if isError(left) {
-			return enrichError(left.(*object.ErrorObj), &EnrichErrorParams{n.Left.GetToken()})
-		}
-		index := Eval(n.Index, env)
-		if isError(index) {
-			return enrichError(index.(*object.ErrorObj), &EnrichErrorParams{n.Index.GetToken()})
-		}
-		res := evalIndexExpression(left, index)
-		if isError(res) {
-			return enrichError(res.(*object.ErrorObj), &EnrichErrorParams{n.GetToken()})
-		}
+	case *ast.CallExpr, *ast.IndexExpr, *ast.GetExpr:
+		res, _ := evalLink(n.(ast.Expr), env)
 		return res
 
-	case *ast.GetExpr:
-		obj := Eval(n.Obj, env)
-		if isError(obj) {
-			return obj
-		}
-		if obj.Type() == object.OBJ_MODULE {
-			module := obj.(*object.ModuleObj)
-			property, ok := module.Vars[n.Name.Value]
-			if !ok {
-				return newError(UseOfUndeclaredError, fmt.Sprintf("propert `%s` does not exist in module `%s`", n.Name.Value, module.Name))
-			}
-			return property
-		}
-		// TODO: make this more generic
-		if obj.Type() == object.OBJ_ITERATOR {
-			iterator := obj.(*object.IteratorObj)
-			switch n.Name.Value {
-
-			case "next":
-				return &object.BuiltinObj{
-					Name: "next",
-					Fn: func(args ...object.Object) object.Object {
-						if len(args) != 0 {
-							return &object.ErrorObj{Error: fmt.Errorf("%w: expected 0 args, got=%d", object.ArityError, len(args))}
-						}
-						return iterator.Iterator.Next()
-					},
-				}
-			case "done":
-				return &object.BuiltinObj{
-					Name: "done",
-					Fn: func(args ...object.Object) object.Object {
-						if len(args) != 0 {
-							return &object.ErrorObj{Error: fmt.Errorf("%w: expected 0 args, got=%d", object.ArityError, len(args))}
-						}
-						return boolObject(iterator.Iterator.Done())
-					},
-				}
-			}
-			return newError(UseOfUndeclaredError, fmt.Sprintf("property `%s` does not exist on `%s`", n.Name.Value, iterator.Inspect()))
-		}
-		return NIL
-
 	case *ast.ListLiteralExpr:
-		list := &object.ListObj{}
-		list.Values = evalExpressions(n.Items, env)
-		return list
+		values, err := evalExpressions(n.Items, env)
+		if err != nil {
+			return err
+		}
+		return &object.ListObj{Values: values}
 
 	case *ast.MapLiteralExpr:
 		mapLit := &object.MapObj{}
@@ -231,6 +254,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 		return mapLit
 
+	case *ast.MatchExpr:
+		return evalMatchExpression(n, env)
+
 	case *ast.BooleanLiteralExpr:
 		return boolObject(n.Value)
 
@@ -314,37 +340,186 @@ func evalIndexExpression(left, index object.Object) object.Object {
 }
 
 func evalIndexStringExpression(left, index object.Object) object.Object {
-	idx := index.(*object.NumberObj).Value
-	if !isIntegral(idx) {
-		return newError(IllegalFloatAsIndexError)
-	}
-
 	str := left.(*object.StringObj).Value
-	maxIdx := len(str) - 1
 
-	if int(idx) > maxIdx || idx < 0 {
-		return newError(IndexOutOfBoundsError)
+	idx, err := normalizeIndex(index.(*object.NumberObj).Value, utf8.RuneCountInString(str))
+	if err != nil {
+		return err
 	}
 
-	// PERF: extremely inefficient. should look for better solution
-	ch := []rune(str)[int(idx)]
-	return &object.StringObj{Value: string(ch)}
+	start := runeOffset(str, idx)
+	_, size := utf8.DecodeRuneInString(str[start:])
+	return &object.StringObj{Value: str[start : start+size]}
 }
 
 func evalIndexListExpression(left, index object.Object) object.Object {
-	idx := index.(*object.NumberObj).Value
+	list := left.(*object.ListObj).Values
+
+	idx, err := normalizeIndex(index.(*object.NumberObj).Value, len(list))
+	if err != nil {
+		return err
+	}
+
+	return list[idx]
+}
+
+// checks that idx is a whole number inside a sequence of the given length.
+// A negative index counts from the end, so -1 is the last element
+func normalizeIndex(idx float64, length int) (int, *object.ErrorObj) {
 	if !isIntegral(idx) {
-		return newError(IllegalFloatAsIndexError)
+		return 0, newError(IllegalFloatAsIndexError)
 	}
 
-	list := left.(*object.ListObj).Values
-	maxIdx := len(list) - 1
+	i := int(idx)
+	if i < 0 {
+		i += length
+	}
+	if i < 0 || i >= length {
+		return 0, newError(IndexOutOfBoundsError, fmt.Sprintf("index %d out of range for length %d", int(idx), length))
+	}
+
+	return i, nil
+}
+
+// returns the byte offset of the rune at index i in str.
+// An index equal to the number of runes returns len(str)
+func runeOffset(str string, i int) int {
+	for offset := range str {
+		if i == 0 {
+			return offset
+		}
+		i--
+	}
+
+	return len(str)
+}
+
+// evaluates `left[start:end:step]`. The bounds are clamped to the length of the
+// sequence, so slicing out of range gives a shorter, or empty, result instead of an error
+func evalSliceExpression(n *ast.IndexExpr, left object.Object, env *object.Environment) object.Object {
+	bounds := [3]object.Object{NIL, NIL, NIL}
+	for i, expr := range []ast.Expr{n.Index, n.End, n.Step} {
+		if expr == nil {
+			continue
+		}
+		bounds[i] = Eval(expr, env)
+		if isError(bounds[i]) {
+			return bounds[i]
+		}
+	}
+
+	switch left := left.(type) {
+	case *object.ListObj:
+		start, end, step, err := sliceIndices(len(left.Values), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+
+		values := []object.Object{}
+		for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+			values = append(values, left.Values[i])
+		}
+		return &object.ListObj{Values: values}
+
+	case *object.StringObj:
+		str := left.Value
+		start, end, step, err := sliceIndices(utf8.RuneCountInString(str), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+
+		if step == 1 {
+			if start >= end {
+				return &object.StringObj{Value: ""}
+			}
+			from := runeOffset(str, start)
+			to := from + runeOffset(str[from:], end-start)
+			return &object.StringObj{Value: str[from:to]}
+		}
+
+		runes := []rune(str)
+		var sliced strings.Builder
+		for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+			sliced.WriteRune(runes[i])
+		}
+		return &object.StringObj{Value: sliced.String()}
+	}
+
+	return newError(TypeError, fmt.Sprintf("%s can't be sliced", left.Inspect()))
+}
+
+// resolves the bounds of a slice over a sequence of the given length. Omitted or nil
+// bounds use the defaults for the direction of step, and negative bounds count from the end
+func sliceIndices(length int, startObj, endObj, stepObj object.Object) (start, end, step int, err *object.ErrorObj) {
+	step, hasStep, err := sliceBound(stepObj)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if !hasStep {
+		step = 1
+	}
+	if step == 0 {
+		return 0, 0, 0, newError(IllegalIndexError, "slice step can't be zero")
+	}
+
+	start, hasStart, err := sliceBound(startObj)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	end, hasEnd, err := sliceBound(endObj)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	// a negative step walks from the end towards the start, and stops
+	// before index -1 when the end is omitted
+	lower, upper := 0, length
+	if step < 0 {
+		lower, upper = -1, length-1
+	}
+	clamp := func(i int) int {
+		if i < 0 {
+			i += length
+		}
+		return max(lower, min(i, upper))
+	}
+
+	switch {
+	case !hasStart && step > 0:
+		start = 0
+	case !hasStart:
+		start = length - 1
+	default:
+		start = clamp(start)
+	}
+
+	switch {
+	case !hasEnd && step > 0:
+		end = length
+	case !hasEnd:
+		end = -1
+	default:
+		end = clamp(end)
+	}
+
+	return start, end, step, nil
+}
 
-	if int(idx) > maxIdx || idx < 0 {
-		return newError(IndexOutOfBoundsError)
+// converts a slice bound to an int. nil is treated as an omitted bound
+func sliceBound(obj object.Object) (int, bool, *object.ErrorObj) {
+	if obj == NIL {
+		return 0, false, nil
 	}
 
-	return list[int(idx)]
+	num, ok := obj.(*object.NumberObj)
+	if !ok {
+		return 0, false, newError(IllegalIndexError, fmt.Sprintf("slice bound must be a number, got %s", obj.Inspect()))
+	}
+	if !isIntegral(num.Value) {
+		return 0, false, newError(IllegalFloatAsIndexError)
+	}
+
+	return int(num.Value), true, nil
 }
 
 func evalIndexMapListExpression(left, index object.Object) object.Object {
@@ -363,34 +538,70 @@ func evalIndexMapListExpression(left, index object.Object) object.Object {
 	return pair.Value
 }
 
+// evaluates `start..end step n` to a range iterator. An inclusive range `start..=end`
+// extends the end by one in the direction of the range
+func evalRangeExpression(n *ast.RangeExpr, env *object.Environment) object.Object {
+	bounds := []float64{}
+	for _, expr := range []ast.Expr{n.Start, n.End, n.Step} {
+		if expr == nil {
+			// default step
+			bounds = append(bounds, 1)
+			continue
+		}
+
+		value := Eval(expr, env)
+		if isError(value) {
+			return value
+		}
+		num, ok := value.(*object.NumberObj)
+		if !ok {
+			return newError(TypeError, fmt.Sprintf("range bounds must be numbers, got %s", value.Inspect()))
+		}
+		bounds = append(bounds, num.Value)
+	}
+
+	start, end, step := bounds[0], bounds[1], bounds[2]
+	if n.Inclusive {
+		if start <= end {
+			end++
+		} else {
+			end--
+		}
+	}
+
+	return object.NewRange(start, end, step)
+}
+
 func evalIterStatement(node *ast.IterStmt, env *object.Environment) object.Object {
 	iterable := Eval(node.Iterable, env)
 	if isError(iterable) {
 		return iterable
 	}
 
-	iterator, err := object.NewIterator(iterable)
-	if err != nil {
-		return err
-	}
+	// errors from the iterator, like a protocol `done` that does not return a
+	// boolean, point at the iterable
+	iterableTok := &EnrichErrorParams{node.Iterable.GetToken()}
 
-	var name *ast.IdentifierExpr
-	if node.Name != nil {
-		ident, ok := node.Name.(*ast.IdentifierExpr)
-		if !ok {
-			return &object.ErrorObj{Error: fmt.Errorf("%w: exptected identifier, got=%s", TypeError, node.Name), Token: node.Name.GetToken()}
-		}
-		name = ident
+	iterator, err := object.NewIterator(interpreter(env), iterable)
+	if err != nil {
+		return enrichError(err, iterableTok)
 	}
+	// a return or an error leaves the loop before the iterator is done
+	defer object.CloseIterator(iterator)
 
 	var result object.Object = NIL
 	for !iterator.Done() {
 		val := iterator.Next()
+		if isError(val) {
+			return enrichError(val.(*object.ErrorObj), iterableTok)
+		}
 
 		scope := object.NewEnv(env)
 
-		if name != nil {
-			scope.DeclareVar(name.Value, val)
+		if node.Name != nil {
+			if res := evalDestructure(node.Name, val, scope); isError(res) {
+				return res
+			}
 		}
 
 		result = evalBlockStatment(node.Body, scope)
@@ -402,6 +613,185 @@ func evalIterStatement(node *ast.IterStmt, env *object.Environment) object.Objec
 	return result
 }
 
+// runs the try body, and the catch body when the body returns a catchable error.
+// The finally body always runs last
+func evalTryStatement(node *ast.TryStmt, env *object.Environment) object.Object {
+	result := Eval(node.Body, env)
+
+	if err, ok := result.(*object.ErrorObj); ok && node.Catch != nil && isCatchable(err) {
+		// the caught error is declared in the same scope as the catch body
+		scope := object.NewEnv(env)
+		if node.Param != nil {
+			scope.DeclareVar(node.Param.Value, errorValue(err))
+		}
+		result = evalBlockStatment(node.Catch, scope)
+	}
+
+	if node.Finally != nil {
+		finally := Eval(node.Finally, env)
+		// an error or a return in finally replaces the result of try and catch
+		if isError(finally) || finally.Type() == object.OBJ_RETURN {
+			return finally
+		}
+	}
+
+	return result
+}
+
+// returns the property name of a caught error
+func evalErrorValueProperty(obj *object.ErrorValueObj, name string) object.Object {
+	switch name {
+	case "message":
+		return &object.StringObj{Value: obj.Message}
+	case "kind":
+		return &object.StringObj{Value: obj.Kind}
+	case "value":
+		if obj.Value == nil {
+			return NIL
+		}
+		return obj.Value
+	case "line", "column":
+		if obj.Token == nil {
+			return NIL
+		}
+		line, col := obj.Token.Pos.Position()
+		if name == "line" {
+			return &object.NumberObj{Value: float64(line)}
+		}
+		return &object.NumberObj{Value: float64(col)}
+	case "file":
+		if obj.Token == nil || obj.Token.Pos.Src == nil || obj.Token.Pos.Src.Path == "" {
+			return NIL
+		}
+		return &object.StringObj{Value: obj.Token.Pos.Src.Path}
+	case "location":
+		if obj.Token == nil {
+			return NIL
+		}
+		return &object.StringObj{Value: obj.Token.Pos.Location()}
+	}
+	return newError(UseOfUndeclaredError, fmt.Sprintf("property `%s` does not exist on `%s`", name, obj.Inspect()))
+}
+
+// binds the parts of value to the variables in pattern. It is an error if the value does not match the pattern
+func evalDestructure(pattern ast.Pattern, value object.Object, env *object.Environment) object.Object {
+	matched, err := matchPattern(pattern, value, env)
+	if err != nil {
+		return enrichError(err, &EnrichErrorParams{pattern.GetToken()})
+	}
+	if !matched {
+		err := newError(DestructureError, fmt.Sprintf("%s does not match %s", value.Inspect(), pattern.String()))
+		return enrichError(err, &EnrichErrorParams{pattern.GetToken()})
+	}
+
+	return value
+}
+
+func evalMatchExpression(node *ast.MatchExpr, env *object.Environment) object.Object {
+	subject := Eval(node.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, arm := range node.Arms {
+		// each arm gets its own scope for the variables bound by the pattern
+		scope := object.NewEnv(env)
+
+		matched, err := matchPattern(arm.Pattern, subject, scope)
+		if err != nil {
+			return enrichError(err, &EnrichErrorParams{arm.Pattern.GetToken()})
+		}
+		if !matched {
+			continue
+		}
+
+		if arm.Guard != nil {
+			guard := evalCondition(arm.Guard, scope)
+			if isError(guard) {
+				return guard
+			}
+			if guard != TRUE {
+				continue
+			}
+		}
+
+		return Eval(arm.Body, scope)
+	}
+
+	err := newError(MatchError, fmt.Sprintf("no arm matched %s", subject.Inspect()))
+	return enrichError(err, &EnrichErrorParams{node.GetToken()})
+}
+
+// tests value against the pattern, and declares the bound variables in scope.
+// returns false if the value does not match
+func matchPattern(pattern ast.Pattern, value object.Object, scope *object.Environment) (bool, *object.ErrorObj) {
+	switch p := pattern.(type) {
+	case *ast.WildcardPattern:
+		return true, nil
+
+	case *ast.BindingPattern:
+		if res := scope.DeclareVar(p.Name.Value, value); isError(res) {
+			return false, res.(*object.ErrorObj)
+		}
+		return true, nil
+
+	case *ast.LiteralPattern:
+		literal := Eval(p.Value, scope)
+		if isError(literal) {
+			return false, literal.(*object.ErrorObj)
+		}
+		return evalBinaryExpression(value, literal, token.EQ) == TRUE, nil
+
+	case *ast.ListPattern:
+		list, ok := value.(*object.ListObj)
+		if !ok || len(list.Values) < len(p.Elements) {
+			return false, nil
+		}
+		if p.Rest == nil && len(list.Values) != len(p.Elements) {
+			return false, nil
+		}
+		for idx, elem := range p.Elements {
+			matched, err := matchPattern(elem, list.Values[idx], scope)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		if p.Rest != nil {
+			// the rest of the list is bound to a new list, so changing it won't affect the original
+			rest := &object.ListObj{Values: slices.Clone(list.Values[len(p.Elements):])}
+			return matchPattern(p.Rest, rest, scope)
+		}
+		return true, nil
+
+	case *ast.MapPattern:
+		mapObj, ok := value.(*object.MapObj)
+		if !ok {
+			return false, nil
+		}
+		for idx, keyExpr := range p.Keys {
+			key := Eval(keyExpr, scope)
+			if isError(key) {
+				return false, key.(*object.ErrorObj)
+			}
+			hashable, ok := key.(object.Hashable)
+			if !ok {
+				return false, newError(IllegalIndexError, fmt.Sprintf("%s is not a valid key", key.Inspect()))
+			}
+			pair, ok := mapObj.Pairs[hashable.HashKey()]
+			if !ok {
+				return false, nil
+			}
+			matched, err := matchPattern(p.Values[idx], pair.Value, scope)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	return false, newError(UnknownNodeError, pattern.String())
+}
+
 func evalAssignment(node *ast.AssignExpr, env *object.Environment) object.Object {
 	val := Eval(node.Value, env)
 	if isError(val) {
@@ -414,6 +804,12 @@ func evalAssignment(node *ast.AssignExpr, env *object.Environment) object.Object
 		return val
 
 	case *ast.IndexExpr:
+		if n.Optional {
+			return newError(IllegalAssignmentError, "can't assign to an optional index")
+		}
+		if n.Slice {
+			return newError(IllegalAssignmentError, "can't assign to a slice")
+		}
 		left := Eval(n.Left, env)
 		if isError(left) {
 			return left
@@ -423,13 +819,21 @@ func evalAssignment(node *ast.AssignExpr, env *object.Environment) object.Object
 			return index
 		}
 
-		return evalIndexAssignment(left, index, val)
+		res := evalIndexAssignment(left, index, val)
+		if isError(res) {
+			return enrichError(res.(*object.ErrorObj), &EnrichErrorParams{n.GetToken()})
+		}
+		return res
 	}
 
 	return newError(IllegalAssignmentError)
 }
 
 func evalIndexAssignment(assignee, index, value object.Object) object.Object {
+	if err := object.CheckNotFrozen(assignee); err != nil {
+		return err
+	}
+
 	if assignee.Type() == object.OBJ_LIST && index.Type() == object.OBJ_NUMBER {
 		return evalIndexListAssignment(index, assignee, value)
 	}
@@ -460,19 +864,14 @@ func evalIndexHashAssignment(index object.Object, assignee object.Object, value
 }
 
 func evalIndexListAssignment(index object.Object, assignee object.Object, value object.Object) object.Object {
-	idx := index.(*object.NumberObj).Value
-
-	// make sure the index is a whole number
-	if !isIntegral(idx) {
-		return newError(IllegalFloatAsIndexError)
-	}
+	list := assignee.(*object.ListObj).Values
 
-	// check if index is out of bounds
-	if int(idx) >= len(assignee.(*object.ListObj).Values) || int(idx) < 0 {
-		return newError(IndexOutOfBoundsError, "check")
+	idx, err := normalizeIndex(index.(*object.NumberObj).Value, len(list))
+	if err != nil {
+		return err
 	}
 
-	assignee.(*object.ListObj).Values[int(idx)] = value
+	list[idx] = value
 	return value
 }
 
@@ -489,11 +888,15 @@ func evalPrintStatment(n *ast.PrintStmt, env *object.Environment) object.Object
 			str.WriteString(", ")
 		}
 	}
-	fmt.Println(str.String())
+	fmt.Fprintln(interpreter(env).out, str.String())
 	return NIL
 }
 
 func evalProgram(stmts []ast.Stmt, env *object.Environment) object.Object {
+	// sets the default interpreter on the global scope, if there is none, so
+	// every scope of the program shares it
+	interpreter(env)
+
 	var result object.Object
 	for _, stmt := range stmts {
 		result = Eval(stmt, env)
@@ -510,6 +913,20 @@ func evalProgram(stmts []ast.Stmt, env *object.Environment) object.Object {
 	return result
 }
 
+// evaluates a condition, and returns an error if the value is not a boolean
+func evalCondition(expr ast.Expr, env *object.Environment) object.Object {
+	condition := Eval(expr, env)
+	if isError(condition) {
+		return condition
+	}
+	if condition.Type() != object.OBJ_BOOL {
+		err := newError(TypeError, condition.Inspect()+" is not of type: boolean")
+		return enrichError(err, &EnrichErrorParams{expr.GetToken()})
+	}
+
+	return condition
+}
+
 func evalBlockStatment(b *ast.BlockStmt, scope *object.Environment) object.Object {
 	var res object.Object = NIL
 
@@ -616,6 +1033,149 @@ func boolObject(b bool) *object.BooleanObj {
 	}
 }
 
+// evaluates a link of a postfix chain like `a?.b.c[0]()`. When an optional
+// link is nil, the rest of the chain is skipped and evaluates to nil
+func evalLink(node ast.Expr, env *object.Environment) (res object.Object, short bool) {
+	switch n := node.(type) {
+	case *ast.CallExpr:
+		callee, short := evalLink(n.Callee, env)
+		if short {
+			return NIL, true
+		}
+		return evalCall(n, callee, env), false
+
+	case *ast.IndexExpr:
+		left, short := evalLink(n.Left, env)
+		if short || (n.Optional && left == NIL) {
+			return NIL, true
+		}
+		return evalIndex(n, left, env), false
+
+	case *ast.GetExpr:
+		obj, short := evalLink(n.Obj, env)
+		if short || (n.Optional && obj == NIL) {
+			return NIL, true
+		}
+		return evalGet(n, obj), false
+	}
+	return Eval(node, env), false
+}
+
+func evalCall(n *ast.CallExpr, callee object.Object, env *object.Environment) object.Object {
+	if isError(callee) {
+		return callee
+	}
+
+	args, named, err := evalCallArguments(n.Arguments, env)
+	if err != nil {
+		return err
+	}
+
+	// calls in tail position are made by applyFunction when the
+	// current call has returned, so the Go stack does not grow
+	if n.Tail {
+		return &tailCall{callee: callee, args: args, named: named, token: &n.Token}
+	}
+
+	in := interpreter(env)
+	if err := in.pushCall(&n.Token); err != nil {
+		return err
+	}
+	res := applyFunction(in, callee, args, named)
+	in.popCall()
+	if isError(res) {
+		return traceCall(res.(*object.ErrorObj), callee, &n.Token)
+	}
+	return res
+}
+
+func evalIndex(n *ast.IndexExpr, left object.Object, env *object.Environment) object.Object {
+	if isError(left) {
+		return enrichError(left.(*object.ErrorObj), &EnrichErrorParams{n.Left.GetToken()})
+	}
+	if n.Slice {
+		res := evalSliceExpression(n, left, env)
+		if isError(res) {
+			return enrichError(res.(*object.ErrorObj), &EnrichErrorParams{n.GetToken()})
+		}
+		return res
+	}
+	index := Eval(n.Index, env)
+	if isError(index) {
+		return enrichError(index.(*object.ErrorObj), &EnrichErrorParams{n.Index.GetToken()})
+	}
+	res := evalIndexExpression(left, index)
+	if isError(res) {
+		return enrichError(res.(*object.ErrorObj), &EnrichErrorParams{n.GetToken()})
+	}
+	return res
+}
+
+func evalGet(n *ast.GetExpr, obj object.Object) object.Object {
+	if isError(obj) {
+		return obj
+	}
+	if obj.Type() == object.OBJ_MODULE {
+		module := obj.(*object.ModuleObj)
+		property, ok := module.Vars[n.Name.Value]
+		if !ok {
+			return newError(UseOfUndeclaredError, fmt.Sprintf("propert `%s` does not exist in module `%s`", n.Name.Value, module.Name))
+		}
+		return property
+	}
+	if obj.Type() == object.OBJ_ERROR_VALUE {
+		res := evalErrorValueProperty(obj.(*object.ErrorValueObj), n.Name.Value)
+		if isError(res) {
+			return enrichError(res.(*object.ErrorObj), &EnrichErrorParams{&n.Name.Token})
+		}
+		return res
+	}
+	// `m.name` is the same as `m["name"]`
+	if obj.Type() == object.OBJ_MAP {
+		return evalIndexMapListExpression(obj, &object.StringObj{Value: n.Name.Value})
+	}
+	// TODO: make this more generic
+	if obj.Type() == object.OBJ_ITERATOR {
+		iterator := obj.(*object.IteratorObj)
+		switch n.Name.Value {
+
+		case "next":
+			return &object.BuiltinObj{
+				Name: "next",
+				Fn: func(args ...object.Object) object.Object {
+					if len(args) != 0 {
+						return &object.ErrorObj{Error: fmt.Errorf("%w: expected 0 args, got=%d", object.ArityError, len(args))}
+					}
+					return iterator.Iterator.Next()
+				},
+			}
+		case "done":
+			return &object.BuiltinObj{
+				Name: "done",
+				Fn: func(args ...object.Object) object.Object {
+					if len(args) != 0 {
+						return &object.ErrorObj{Error: fmt.Errorf("%w: expected 0 args, got=%d", object.ArityError, len(args))}
+					}
+					return boolObject(iterator.Iterator.Done())
+				},
+			}
+		case "close":
+			return &object.BuiltinObj{
+				Name: "close",
+				Fn: func(args ...object.Object) object.Object {
+					if len(args) != 0 {
+						return &object.ErrorObj{Error: fmt.Errorf("%w: expected 0 args, got=%d", object.ArityError, len(args))}
+					}
+					object.CloseIterator(iterator.Iterator)
+					return NIL
+				},
+			}
+		}
+		return newError(UseOfUndeclaredError, fmt.Sprintf("property `%s` does not exist on `%s`", n.Name.Value, iterator.Inspect()))
+	}
+	return NIL
+}
+
 func unwrapReturn(obj object.Object) object.Object {
 	if ret, ok := obj.(*object.ReturnObj); ok {
 		return ret.Value
@@ -623,27 +1183,76 @@ func unwrapReturn(obj object.Object) object.Object {
 	return obj
 }
 
-// On error, will return an error as first, and only item in slice
-func evalExpressions(exprs []ast.Expr, env *object.Environment) []object.Object {
+// evaluates every expression, and returns the first error
+func evalExpressions(exprs []ast.Expr, env *object.Environment) ([]object.Object, *object.ErrorObj) {
 	list := make([]object.Object, 0)
 	for _, expr := range exprs {
+		if spread, ok := expr.(*ast.SpreadExpr); ok {
+			items, err := evalSpread(spread, env)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, items...)
+			continue
+		}
+
 		obj := Eval(expr, env)
 		if isError(obj) {
-			return []object.Object{obj}
+			return nil, obj.(*object.ErrorObj)
 		}
 		list = append(list, obj)
 	}
 
-	return list
+	return list, nil
 }
 
-func evalKeyValueExpressions(expressionMap map[ast.Expr]ast.Expr, env *object.Environment) (
+// evaluates the spread value and collects every item of its iterator
+func evalSpread(spread *ast.SpreadExpr, env *object.Environment) ([]object.Object, *object.ErrorObj) {
+	value := Eval(spread.Value, env)
+	if isError(value) {
+		return nil, value.(*object.ErrorObj)
+	}
+
+	return spreadItems(interpreter(env), spread, value)
+}
+
+func spreadItems(in *Interpreter, spread *ast.SpreadExpr, value object.Object) ([]object.Object, *object.ErrorObj) {
+	iterator, iterErr := object.NewIterator(in, value)
+	if iterErr != nil {
+		err := newError(SpreadError, fmt.Sprintf("%s is not iterable", value.Type()))
+		err.Token = spread.GetToken()
+		return nil, err
+	}
+	defer object.CloseIterator(iterator)
+
+	items := []object.Object{}
+	for !iterator.Done() {
+		item := iterator.Next()
+		if isError(item) {
+			return nil, item.(*object.ErrorObj)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func evalKeyValueExpressions(keyValues []*ast.KeyValue, env *object.Environment) (
 	map[object.HashKey]object.KeyValuePair,
 	*object.ErrorObj,
 ) {
-	pairs := make(map[object.HashKey]object.KeyValuePair, len(expressionMap))
+	pairs := make(map[object.HashKey]object.KeyValuePair, len(keyValues))
 
-	for key, value := range expressionMap {
+	for _, kv := range keyValues {
+		if kv.Key == nil {
+			err := evalMapSpread(kv.Value.(*ast.SpreadExpr), pairs, env)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		key, value := kv.Key, kv.Value
 		k := Eval(key, env)
 		if isError(k) {
 			return nil, k.(*object.ErrorObj)
@@ -657,7 +1266,7 @@ func evalKeyValueExpressions(expressionMap map[ast.Expr]ast.Expr, env *object.En
 		}
 
 		v := Eval(value, env)
-		if isError(k) {
+		if isError(v) {
 			return nil, v.(*object.ErrorObj)
 		}
 
@@ -667,11 +1276,109 @@ func evalKeyValueExpressions(expressionMap map[ast.Expr]ast.Expr, env *object.En
 	return pairs, nil
 }
 
-func applyFunction(callee object.Object, args []object.Object) object.Object {
+// spreads a map, or an iterable of [key, value] pairs, into pairs.
+// Later entries overwrite earlier ones with the same key
+func evalMapSpread(spread *ast.SpreadExpr, pairs map[object.HashKey]object.KeyValuePair, env *object.Environment) *object.ErrorObj {
+	value := Eval(spread.Value, env)
+	if isError(value) {
+		return value.(*object.ErrorObj)
+	}
+
+	if m, ok := value.(*object.MapObj); ok {
+		maps.Copy(pairs, m.Pairs)
+		return nil
+	}
+
+	items, err := spreadItems(interpreter(env), spread, value)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		pair, ok := item.(*object.ListObj)
+		if !ok || len(pair.Values) != 2 {
+			err := newError(SpreadError, fmt.Sprintf("expected [key, value] pair, got %s", item.Inspect()))
+			err.Token = spread.GetToken()
+			return err
+		}
+
+		hash, ok := pair.Values[0].(object.Hashable)
+		if !ok {
+			err := newError(IllegalIndexError, fmt.Sprintf("can't use %s as key", pair.Values[0].Inspect()))
+			err.Token = spread.GetToken()
+			return err
+		}
+		pairs[hash.HashKey()] = object.KeyValuePair{Key: pair.Values[0], Value: pair.Values[1]}
+	}
+
+	return nil
+}
+
+// argument passed by name at the call site, `f(name: value)`
+type namedArg struct {
+	name  string
+	value object.Object
+}
+
+// tailCall is returned by a call in tail position, and is made by the
+// applyFunction of the function it is returned from
+type tailCall struct {
+	callee object.Object
+	args   []object.Object
+	named  []namedArg
+	token  *token.Token
+}
+
+func (t *tailCall) Type() object.ObjectType { return object.OBJ_TAIL_CALL }
+func (t *tailCall) Inspect() string         { return "tail call" }
+
+// evaluates the arguments of a call, and splits them into positional and named arguments
+func evalCallArguments(exprs []ast.Expr, env *object.Environment) ([]object.Object, []namedArg, *object.ErrorObj) {
+	args := make([]object.Object, 0, len(exprs))
+	named := []namedArg{}
+
+	for _, expr := range exprs {
+		if namedExpr, ok := expr.(*ast.NamedArgumentExpr); ok {
+			value := Eval(namedExpr.Value, env)
+			if isError(value) {
+				return nil, nil, value.(*object.ErrorObj)
+			}
+			named = append(named, namedArg{name: namedExpr.Name.Value, value: value})
+			continue
+		}
+
+		if spread, ok := expr.(*ast.SpreadExpr); ok {
+			items, err := evalSpread(spread, env)
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, items...)
+			continue
+		}
+
+		value := Eval(expr, env)
+		if isError(value) {
+			return nil, nil, value.(*object.ErrorObj)
+		}
+		args = append(args, value)
+	}
+
+	return args, named, nil
+}
+
+func applyFunction(in *Interpreter, callee object.Object, args []object.Object, named []namedArg) object.Object {
 
 	switch callee.Type() {
 	case object.OBJ_BUILTIN:
-		val := callee.(*object.BuiltinObj).Fn(args...)
+		builtin := callee.(*object.BuiltinObj)
+		if len(named) > 0 {
+			return newError(object.ArityError, fmt.Sprintf("builtin `%s` does not take named arguments", builtin.Name))
+		}
+		var val object.Object
+		if builtin.ContextFn != nil {
+			val = builtin.ContextFn(in, args...)
+		} else {
+			val = builtin.Fn(args...)
+		}
 		if val != nil {
 			return val
 		}
@@ -679,25 +1386,169 @@ func applyFunction(callee object.Object, args []object.Object) object.Object {
 
 	case object.OBJ_FUNCTION_LITERAL:
 		fn := callee.(*object.FnLiteralObj)
-		if len(fn.Parameters) != len(args) {
-			return &object.ErrorObj{
-				Error: fmt.Errorf("expected number of args=%d, got=%d\n",
-					len(fn.Parameters), len(args)),
+		// the token of the tail call being made, nil for the first call
+		var callTok *token.Token
+
+		for {
+			frame := &object.Frame{}
+			scope := object.NewFunctionEnv(fn.Env, frame)
+			if err := bindArguments(fn, args, named, scope); err != nil {
+				if callTok != nil {
+					return traceCall(err, fn, callTok)
+				}
+				return err
 			}
-		}
-		scope := object.NewEnv(fn.Env)
-		for idx, arg := range fn.Parameters {
-			scope.DeclareVar(arg.Value, args[idx])
-		}
 
-		evaluated := evalBlockStatment(fn.Body, scope)
-		return unwrapReturn(evaluated)
+			if fn.Generator {
+				return &object.IteratorObj{Iterator: object.NewGenerator(generatorBody(fn, frame, scope))}
+			}
+
+			evaluated := evalBlockStatment(fn.Body, scope)
+			evaluated = runDeferred(frame, evaluated)
+			if err, ok := evaluated.(*object.ErrorObj); ok && errors.Is(err.Error, PropagatedError) {
+				return err.Value
+			}
+			// the calls replaced by tail calls are left out of the trace
+			if isError(evaluated) && callTok != nil {
+				return traceCall(evaluated.(*object.ErrorObj), fn, callTok)
+			}
+
+			result := unwrapReturn(evaluated)
+			tail, ok := result.(*tailCall)
+			if !ok {
+				return result
+			}
+
+			// reuse this loop when the tail call is to another function literal
+			next, ok := tail.callee.(*object.FnLiteralObj)
+			if !ok {
+				if err := in.pushCall(tail.token); err != nil {
+					return err
+				}
+				res := applyFunction(in, tail.callee, tail.args, tail.named)
+				in.popCall()
+				if isError(res) {
+					return traceCall(res.(*object.ErrorObj), tail.callee, tail.token)
+				}
+				return res
+			}
+			// the tail call takes the place of this call, so the depth is the same
+			fn, args, named, callTok = next, tail.args, tail.named, tail.token
+		}
 
 	default:
 		return newError(TypeError, fmt.Sprintf("expected function, got=%s\n", callee.Type()))
 	}
 }
 
+// returns the sequence of values yielded by the body of fn. The body runs when
+// the first value is pulled. A return ends the sequence, and an error is
+// passed on as the last value. An error value propagated with `value?` is
+// the last value, like it is the return value of a function
+func generatorBody(fn *object.FnLiteralObj, frame *object.Frame, scope *object.Environment) iter.Seq[object.Object] {
+	return func(yield func(object.Object) bool) {
+		frame.Yield = yield
+
+		evaluated := evalBlockStatment(fn.Body, scope)
+		evaluated = runDeferred(frame, evaluated)
+		if err, ok := evaluated.(*object.ErrorObj); ok {
+			if errors.Is(err.Error, PropagatedError) {
+				yield(err.Value)
+			} else {
+				yield(err)
+			}
+		}
+	}
+}
+
+// evaluates the deferred expressions of frame in reverse order. They run even when
+// the function fails. The first error, from the body or a deferred expression,
+// is the result of the call
+func runDeferred(frame *object.Frame, result object.Object) object.Object {
+	for i := len(frame.Defers) - 1; i >= 0; i-- {
+		deferred := frame.Defers[i]
+		res := Eval(deferred.Expr, deferred.Env)
+		if isError(res) && !isError(result) {
+			result = res
+		}
+	}
+	return result
+}
+
+// declares the parameters of fn in scope. Positional arguments are bound first, then
+// named arguments. Parameters that did not get an argument use their default value
+func bindArguments(fn *object.FnLiteralObj, args []object.Object, named []namedArg, scope *object.Environment) *object.ErrorObj {
+	values := make(map[string]object.Object, len(fn.Parameters))
+
+	positional := fn.Parameters
+	var rest *ast.Parameter
+	if len(positional) > 0 && positional[len(positional)-1].Rest {
+		rest = positional[len(positional)-1]
+		positional = positional[:len(positional)-1]
+	}
+
+	if len(args) > len(positional) && rest == nil {
+		return newError(object.ArityError, fmt.Sprintf("%s takes %d arguments, got %d",
+			functionName(fn), len(positional), len(args)))
+	}
+
+	for idx, arg := range args {
+		if idx >= len(positional) {
+			break
+		}
+		values[positional[idx].Name.Value] = arg
+	}
+
+	for _, arg := range named {
+		idx := slices.IndexFunc(positional, func(p *ast.Parameter) bool { return p.Name.Value == arg.name })
+		if idx < 0 {
+			return newError(object.ArityError, fmt.Sprintf("%s has no parameter `%s`", functionName(fn), arg.name))
+		}
+		if _, ok := values[arg.name]; ok {
+			return newError(object.ArityError, fmt.Sprintf("%s got multiple values for parameter `%s`", functionName(fn), arg.name))
+		}
+		values[arg.name] = arg.value
+	}
+
+	missing := []string{}
+	for _, param := range positional {
+		value, ok := values[param.Name.Value]
+		if !ok && param.Default != nil {
+			// defaults are evaluated in the function scope, so they can refer to the parameters before them
+			value = Eval(param.Default, scope)
+			if isError(value) {
+				return value.(*object.ErrorObj)
+			}
+		} else if !ok {
+			missing = append(missing, "`"+param.Name.Value+"`")
+			continue
+		}
+		scope.DeclareVar(param.Name.Value, value)
+	}
+	if len(missing) > 0 {
+		return newError(object.ArityError, fmt.Sprintf("%s is missing arguments for %s",
+			functionName(fn), strings.Join(missing, ", ")))
+	}
+
+	if rest != nil {
+		list := &object.ListObj{Values: []object.Object{}}
+		if len(args) > len(positional) {
+			list.Values = slices.Clone(args[len(positional):])
+		}
+		scope.DeclareVar(rest.Name.Value, list)
+	}
+
+	return nil
+}
+
+// returns a name for fn that can be used in error messages
+func functionName(fn *object.FnLiteralObj) string {
+	if fn.Name == "" {
+		return "anonymous function"
+	}
+	return fmt.Sprintf("function `%s`", fn.Name)
+}
+
 // helper to check if value is a whole number
 func isIntegral(val float64) bool {
 	return val == float64(int(val))
@@ -1,13 +1,18 @@
 package evaluator
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/fredrikkvalvik/temp-lang/pkg/lexer"
 	"github.com/fredrikkvalvik/temp-lang/pkg/object"
 	"github.com/fredrikkvalvik/temp-lang/pkg/parser"
+	"github.com/fredrikkvalvik/temp-lang/pkg/resolver"
 	"github.com/fredrikkvalvik/temp-lang/pkg/tester"
 )
 
@@ -316,6 +321,1028 @@ func TestIdentifer(t *testing.T) {
 
 }
 
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{`match 1 { 1 => "one", _ => "other" }`,
+			object.OBJ_STRING, "one"},
+		{`match 2 { 1 => "one", _ => "other" }`,
+			object.OBJ_STRING, "other"},
+		{`match -1 { -1 => true, _ => false }`,
+			object.OBJ_BOOL, true},
+		{`match [1, 2] { [a] => a, [a, b] => a + b }`,
+			object.OBJ_NUMBER, float64(3)},
+		{`match [1, [2, 3]] { [_, [a, b]] => a * b }`,
+			object.OBJ_NUMBER, float64(6)},
+		{`match {"name": "bob", "age": 10} { {"name": n, age} => n + str(age) }`,
+			object.OBJ_STRING, "bob10"},
+		{`match {"name": "bob"} { {age} => age, {name} => name }`,
+			object.OBJ_STRING, "bob"},
+		{`match 10 { n if n > 5 => "big", n => "small" }`,
+			object.OBJ_STRING, "big"},
+		{`match 1 { n if n > 5 => "big", n => "small" }`,
+			object.OBJ_STRING, "small"},
+		{`match 1 { n => { let double = n * 2; double } }`,
+			object.OBJ_NUMBER, float64(2)},
+		{`match 1 { 2 => "two" }`,
+			object.OBJ_ERROR, MatchError},
+		{`match 1 { n if n => "truthy" }`,
+			object.OBJ_ERROR, TypeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType)
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{"let [a, b] = [1, 2]; a + b",
+			object.OBJ_NUMBER, float64(3)},
+		{"let [a, ...rest] = [1, 2, 3]; len(rest)",
+			object.OBJ_NUMBER, float64(2)},
+		{"let [a, ...rest] = [1]; len(rest)",
+			object.OBJ_NUMBER, float64(0)},
+		{"let [_, [a, b]] = [0, [1, 2]]; a + b",
+			object.OBJ_NUMBER, float64(3)},
+		{`let {name, age} = {"name": "bob", "age": 10}; name + str(age)`,
+			object.OBJ_STRING, "bob10"},
+		{`let {"name": n} = {"name": "bob"}; n`,
+			object.OBJ_STRING, "bob"},
+		{`let sum = 0
+			each [k, v] : [[1, 2], [3, 4]] {
+				sum = sum + k * v
+			}
+			sum`,
+			object.OBJ_NUMBER, float64(14)},
+		{`let names = ""
+			each {name} : [{"name": "a"}, {"name": "b"}] {
+				names = names + name
+			}
+			names`,
+			object.OBJ_STRING, "ab"},
+		{"let [a, b] = [1]",
+			object.OBJ_ERROR, DestructureError},
+		{"let [a] = 1",
+			object.OBJ_ERROR, DestructureError},
+		{`let {name} = {}`,
+			object.OBJ_ERROR, DestructureError},
+		{`each [a, b] : [1] {}`,
+			object.OBJ_ERROR, DestructureError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType)
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestLambdaExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{"let double = x => x * 2; double(4)",
+			object.OBJ_NUMBER, float64(8)},
+		{"let add = (a, b) => a + b; add(1, 2)",
+			object.OBJ_NUMBER, float64(3)},
+		{"let one = () => 1; one()",
+			object.OBJ_NUMBER, float64(1)},
+		{"let inc = (x, by = 1) => x + by; inc(1)",
+			object.OBJ_NUMBER, float64(2)},
+		{"let adder = a => b => a + b; adder(3)(4)",
+			object.OBJ_NUMBER, float64(7)},
+		{"fn apply(f, v) { return f(v) }\napply(x => x * x, 5)",
+			object.OBJ_NUMBER, float64(25)},
+		{"let n = 10; let f = x => x + n; f(1)",
+			object.OBJ_NUMBER, float64(11)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestIfExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{"let x = if true { 1 } else { 2 }; x",
+			object.OBJ_NUMBER, float64(1)},
+		{"let x = if false { 1 } else { 2 }; x",
+			object.OBJ_NUMBER, float64(2)},
+		{"let x = if false { 1 }; x",
+			object.OBJ_NIL, nil},
+		{"let n = 5; let x = if n > 10 { 1 } else if n > 3 { 2 } else { 3 }; x",
+			object.OBJ_NUMBER, float64(2)},
+		{"let x = if true { let a = 2; a * 2 } else { 0 }; x",
+			object.OBJ_NUMBER, float64(4)},
+		{"fn f() { let x = if true { return 1 } else { 2 }; return 3 }\nf()",
+			object.OBJ_NUMBER, float64(1)},
+		{"true ? 1 : 2",
+			object.OBJ_NUMBER, float64(1)},
+		{"false ? 1 : 2",
+			object.OBJ_NUMBER, float64(2)},
+		{"let n = 5; n > 10 ? 1 : n > 3 ? 2 : 3",
+			object.OBJ_NUMBER, float64(2)},
+
+		{"let x = if 1 { 1 }",
+			object.OBJ_ERROR, TypeError},
+		{`"a" ? 1 : 2`,
+			object.OBJ_ERROR, TypeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+			if tt.expectedType == object.OBJ_NIL {
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestOptionalChaining(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{`let cfg = {"db": {"host": "localhost"}}; cfg["db"]?["host"]`,
+			object.OBJ_STRING, "localhost"},
+		{`let cfg = {}; cfg["db"]?["host"]`,
+			object.OBJ_NIL, nil},
+		{`let cfg = {}; cfg["db"]?.host`,
+			object.OBJ_NIL, nil},
+		{`let cfg = {}; cfg["db"]?["host"] ?? "default"`,
+			object.OBJ_STRING, "default"},
+		{`let cfg = {"port": 0}; cfg["port"] ?? 80`,
+			object.OBJ_NUMBER, float64(0)},
+		{`false ?? true`,
+			object.OBJ_BOOL, false},
+		{`let calls = 0; let f = () => calls = calls + 1; 1 ?? f(); calls`,
+			object.OBJ_NUMBER, float64(0)},
+		{`let calls = 0; let f = () => calls = calls + 1; let m = {}; m["a"]?[f()]; calls`,
+			object.OBJ_NUMBER, float64(0)},
+		{`let cfg = {"db": {"host": "localhost"}}; cfg?.db?.host`,
+			object.OBJ_STRING, "localhost"},
+		// a nil optional link skips the rest of the chain
+		{`let cfg = {}["cfg"]; cfg?["db"]["host"]`,
+			object.OBJ_NIL, nil},
+		{`let cfg = {}["cfg"]; cfg?.db.host[0]`,
+			object.OBJ_NIL, nil},
+		{`let cfg = {}["cfg"]; cfg?.connect()`,
+			object.OBJ_NIL, nil},
+		{`let cfg = {"db": {}}; cfg.db.host?.port`,
+			object.OBJ_NIL, nil},
+
+		{`let cfg = {}; cfg["db"]["host"]`,
+			object.OBJ_ERROR, TypeError},
+		// parentheses end the chain
+		{`let cfg = {}["cfg"]; (cfg?["db"])["host"]`,
+			object.OBJ_ERROR, TypeError},
+		{`let cfg = {}; cfg?["db"] = 1`,
+			object.OBJ_ERROR, IllegalAssignmentError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			switch tt.expectedType {
+			case object.OBJ_ERROR:
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+			case object.OBJ_NIL:
+			default:
+				testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+			}
+		})
+	}
+}
+
+func TestIndexAndSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{"[1, 2, 3][-1]",
+			object.OBJ_NUMBER, float64(3)},
+		{"[1, 2, 3][-3]",
+			object.OBJ_NUMBER, float64(1)},
+		{`"héllo"[1]`,
+			object.OBJ_STRING, "é"},
+		{`"héllo"[-1]`,
+			object.OBJ_STRING, "o"},
+		{"let xs = [1, 2, 3]; xs[-1] = 4; xs[2]",
+			object.OBJ_NUMBER, float64(4)},
+
+		{"str([0, 1, 2, 3, 4][1:3])",
+			object.OBJ_STRING, "[1, 2]"},
+		{"str([0, 1, 2, 3, 4][:-1])",
+			object.OBJ_STRING, "[0, 1, 2, 3]"},
+		{"str([0, 1, 2, 3, 4][2:])",
+			object.OBJ_STRING, "[2, 3, 4]"},
+		{"str([0, 1, 2, 3, 4][::2])",
+			object.OBJ_STRING, "[0, 2, 4]"},
+		{"str([0, 1, 2, 3, 4][::-1])",
+			object.OBJ_STRING, "[4, 3, 2, 1, 0]"},
+		{"str([0, 1, 2, 3, 4][3:0:-1])",
+			object.OBJ_STRING, "[3, 2, 1]"},
+		{"str([0, 1, 2][5:10])",
+			object.OBJ_STRING, "[]"},
+		{"str([0, 1, 2][-10:2])",
+			object.OBJ_STRING, "[0, 1]"},
+		{`"héllo wörld"[1:5]`,
+			object.OBJ_STRING, "éllo"},
+		{`"héllo wörld"[7:]`,
+			object.OBJ_STRING, "örld"},
+		{`"héllo"[::-1]`,
+			object.OBJ_STRING, "olléh"},
+		{`"abc"[10:]`,
+			object.OBJ_STRING, ""},
+		{"let xs = [1, 2]; let ys = xs[:]; ys[0] = 3; xs[0]",
+			object.OBJ_NUMBER, float64(1)},
+
+		{"[1, 2, 3][3]",
+			object.OBJ_ERROR, IndexOutOfBoundsError},
+		{"[1, 2, 3][-4]",
+			object.OBJ_ERROR, IndexOutOfBoundsError},
+		{`"abc"[3]`,
+			object.OBJ_ERROR, IndexOutOfBoundsError},
+		{"[1, 2, 3][1.5:]",
+			object.OBJ_ERROR, IllegalFloatAsIndexError},
+		{"[1, 2, 3][::0]",
+			object.OBJ_ERROR, IllegalIndexError},
+		{`[1, 2, 3]["a":]`,
+			object.OBJ_ERROR, IllegalIndexError},
+		{`({})[1:]`,
+			object.OBJ_ERROR, TypeError},
+		{"let xs = [1]; xs[0:1] = 2",
+			object.OBJ_ERROR, IllegalAssignmentError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestRangeExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{"str([...0..3])",
+			object.OBJ_STRING, "[0, 1, 2]"},
+		{"str([...0..=3])",
+			object.OBJ_STRING, "[0, 1, 2, 3]"},
+		{"str([...3..0])",
+			object.OBJ_STRING, "[3, 2, 1]"},
+		{"str([...3..=0])",
+			object.OBJ_STRING, "[3, 2, 1, 0]"},
+		{"str([...0..10 step 3])",
+			object.OBJ_STRING, "[0, 3, 6, 9]"},
+		{"str([...10..0 step 4])",
+			object.OBJ_STRING, "[10, 6, 2]"},
+		{"str([...2..2])",
+			object.OBJ_STRING, "[]"},
+		{"let sum = 0; each i : 1..=4 { sum = sum + i }\nsum",
+			object.OBJ_NUMBER, float64(10)},
+		{"let range = 0; let sum = 0; each i : 0..3 { sum = sum + i }\nsum",
+			object.OBJ_NUMBER, float64(3)},
+
+		{"0..1.5",
+			object.OBJ_ERROR, object.TypeError},
+		{"0..3 step 0",
+			object.OBJ_ERROR, object.TypeError},
+		{"0..3 step -1",
+			object.OBJ_ERROR, object.TypeError},
+		{`"a"..3`,
+			object.OBJ_ERROR, TypeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{`const cfg = freeze({"db": {"host": "localhost"}}); cfg["db"]["host"]`,
+			object.OBJ_STRING, "localhost"},
+		{"let xs = freeze([1, 2]); len(xs)",
+			object.OBJ_NUMBER, float64(2)},
+		{"let xs = freeze([1, 2]); let ys = [...xs]; push(ys, 3); len(ys)",
+			object.OBJ_NUMBER, float64(3)},
+		{"let xs = [1]; push(xs, xs); freeze(xs); len(xs)",
+			object.OBJ_NUMBER, float64(2)},
+		{"freeze(1)",
+			object.OBJ_NUMBER, float64(1)},
+
+		{"let xs = freeze([1]); push(xs, 2)",
+			object.OBJ_ERROR, object.FrozenError},
+		{"let xs = freeze([1]); pop(xs)",
+			object.OBJ_ERROR, object.FrozenError},
+		{"let xs = freeze([1]); xs[0] = 2",
+			object.OBJ_ERROR, object.FrozenError},
+		{`let m = freeze({}); m["a"] = 1`,
+			object.OBJ_ERROR, object.FrozenError},
+		{`let m = freeze({"a": [1]}); push(m["a"], 2)`,
+			object.OBJ_ERROR, object.FrozenError},
+		{`let m = freeze({"a": {"b": 1}}); m["a"]["b"] = 2`,
+			object.OBJ_ERROR, object.FrozenError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestTryStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{"let x = 0; try { throw \"boom\" } catch (e) { x = 1 }\nx",
+			object.OBJ_NUMBER, float64(1)},
+		{"let m = \"\"; try { throw \"boom\" } catch (e) { m = e.message }\nm",
+			object.OBJ_STRING, "boom"},
+		{"let k = \"\"; try { throw 1 } catch (e) { k = e.kind }\nk",
+			object.OBJ_STRING, "Error"},
+		{"let v = 0; try { throw [1, 2] } catch (e) { v = e.value[1] }\nv",
+			object.OBJ_NUMBER, float64(2)},
+		{"let k = \"\"; try { [1][5] } catch (e) { k = e.kind }\nk",
+			object.OBJ_STRING, "IndexOutOfBoundsError"},
+		{"let k = \"\"; try { 1 and true } catch (e) { k = e.kind }\nk",
+			object.OBJ_STRING, "TypeError"},
+		{"let k = \"\"; try { push(freeze([]), 1) } catch (e) { k = e.kind }\nk",
+			object.OBJ_STRING, "FrozenError"},
+		{"let l = 0; try {\n\n  throw 1\n} catch (e) { l = e.line }\nl",
+			object.OBJ_NUMBER, float64(3)},
+		{"let l = \"\"; try {\n\n  throw 1\n} catch (e) { l = e.location }\nl",
+			object.OBJ_STRING, "3:9"},
+		{"let f = \"\"; try { throw 1 } catch (e) { f = e.file ?? \"no file\" }\nf",
+			object.OBJ_STRING, "no file"},
+		{"let x = 0; try { x = 1 } catch (e) { x = 2 }\nx",
+			object.OBJ_NUMBER, float64(1)},
+		{"let x = 0; try { throw 1 } catch { x = 2 }\nx",
+			object.OBJ_NUMBER, float64(2)},
+		{"let x = 0; try { x = 1 } finally { x = x + 1 }\nx",
+			object.OBJ_NUMBER, float64(2)},
+		{"let x = 0; try { throw 1 } catch (e) { x = 1 } finally { x = x + 1 }\nx",
+			object.OBJ_NUMBER, float64(2)},
+		{"let f = fn() { try { return 1 } finally { return 2 } }\nf()",
+			object.OBJ_NUMBER, float64(2)},
+		{"let f = fn() { try { throw 1 } catch (e) { return e.value + 1 } }\nf()",
+			object.OBJ_NUMBER, float64(2)},
+		{"let k = \"\"; try { try { [][1] } catch (e) { throw e } } catch (e) { k = e.kind }\nk",
+			object.OBJ_STRING, "IndexOutOfBoundsError"},
+		{"let x = 0; try { try { throw 1 } finally { x = 1 } } catch { x = x + 1 }\nx",
+			object.OBJ_NUMBER, float64(2)},
+
+		{"throw \"boom\"",
+			object.OBJ_ERROR, ThrownError},
+		{"try { throw 1 } finally { }",
+			object.OBJ_ERROR, ThrownError},
+		{"try { throw 1 } catch (e) { e.line + [] }",
+			object.OBJ_ERROR, IllegalOperationError},
+		{"try { throw 1 } catch (e) { e.nope }",
+			object.OBJ_ERROR, UseOfUndeclaredError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestErrorValues(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{`error("boom").message`,
+			object.OBJ_STRING, "boom"},
+		{`error("boom").kind`,
+			object.OBJ_STRING, "Error"},
+		{`error(404).value`,
+			object.OBJ_NUMBER, float64(404)},
+		{`is_error(error("boom"))`,
+			object.OBJ_BOOL, true},
+		{`is_error("boom")`,
+			object.OBJ_BOOL, false},
+		{`let e = error("boom"); 1`,
+			object.OBJ_NUMBER, float64(1)},
+
+		{`let f = fn(x) { let y = x?; return y + 1 }; f(1)`,
+			object.OBJ_NUMBER, float64(2)},
+		{`let f = fn(x) { let y = x?; return y + 1 }; f(error("boom")).message`,
+			object.OBJ_STRING, "boom"},
+		{`let f = fn(x) { return 1 + x? }; is_error(f(error("boom")))`,
+			object.OBJ_BOOL, true},
+		{`let f = fn(x) { return [x?] }; f(error("boom")).message`,
+			object.OBJ_STRING, "boom"},
+		{`let inner = fn() { return error("boom") }
+let outer = fn() { inner()?; return "ok" }
+outer().message`,
+			object.OBJ_STRING, "boom"},
+		{`let f = fn(x) { try { x? } catch { return "caught" }
+return "done" }
+f(error("boom")).message`,
+			object.OBJ_STRING, "boom"},
+		{`let x = 0; let f = fn(e) { try { e? } finally { x = 1 } }; f(error("boom")); x`,
+			object.OBJ_NUMBER, float64(1)},
+		{`let m = ""; try { throw error("boom") } catch (e) { m = e.message }
+m`,
+			object.OBJ_STRING, "boom"},
+
+		{`error()`,
+			object.OBJ_ERROR, object.ArityError},
+		{`throw error("boom")`,
+			object.OBJ_ERROR, ThrownError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestDefer(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{`let log = []
+let f = fn() { defer push(log, 1)
+push(log, 2) }
+f()
+log[0]`,
+			object.OBJ_NUMBER, float64(2)},
+		{`let log = []
+let f = fn() { defer push(log, 1)
+defer push(log, 2) }
+f()
+log[0] * 10 + log[1]`,
+			object.OBJ_NUMBER, float64(21)},
+		{`let log = []
+let f = fn() { defer push(log, 1)
+return 5 }
+f() + len(log)`,
+			object.OBJ_NUMBER, float64(6)},
+		{`let log = []
+let f = fn() { defer push(log, 1)
+[][1] }
+try { f() } catch {}
+len(log)`,
+			object.OBJ_NUMBER, float64(1)},
+		{`let x = 0
+let f = fn() { defer x = x + 1
+each i : 0..3 { defer x = x * 2 } }
+f()
+x`,
+			object.OBJ_NUMBER, float64(1)},
+		{`let x = 0
+let f = fn() { defer x = 10
+x = 1
+return x }
+f() + x`,
+			object.OBJ_NUMBER, float64(11)},
+		{`let x = 0
+let f = fn(e) { defer x = 1
+e? }
+f(error("boom"))
+x`,
+			object.OBJ_NUMBER, float64(1)},
+
+		{`let f = fn() { defer [][1]
+return 1 }
+f()`,
+			object.OBJ_ERROR, IndexOutOfBoundsError},
+		{`let f = fn() { defer 1 + true
+[][1] }
+f()`,
+			object.OBJ_ERROR, IndexOutOfBoundsError},
+		{`defer 1`,
+			object.OBJ_ERROR, IllegalOperationError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{`let gen = fn() { yield 1
+yield 2 }
+let sum = 0
+each n : gen() { sum = sum + n }
+sum`,
+			object.OBJ_NUMBER, float64(3)},
+		{`let gen = fn() { yield 1
+yield 2 }
+let it = gen()
+it.next() * 10 + it.next()`,
+			object.OBJ_NUMBER, float64(12)},
+		{`let gen = fn() { yield 1 }
+let it = gen()
+it.next()
+it.done()`,
+			object.OBJ_BOOL, true},
+		{`let gen = fn() { yield 1 }
+let it = gen()
+it.done()`,
+			object.OBJ_BOOL, false},
+		{`let gen = fn() { return
+yield 1 }
+gen().done()`,
+			object.OBJ_BOOL, true},
+		{`let gen = fn(n) { each i : 0..n { yield i * i } }
+let xs = [...gen(4)]
+xs[3]`,
+			object.OBJ_NUMBER, float64(9)},
+		{`let gen = fn() { yield 1
+return 5
+yield 2 }
+len([...gen()])`,
+			object.OBJ_NUMBER, float64(1)},
+		{`let gen = fn() { let i = 0
+while true { yield i
+i = i + 1 } }
+let it = gen()
+it.next()
+it.next()
+it.next()`,
+			object.OBJ_NUMBER, float64(2)},
+		{`let started = false
+let gen = fn() { started = true
+yield 1 }
+let it = gen()
+started`,
+			object.OBJ_BOOL, false},
+		{`let log = []
+let gen = fn() { defer push(log, "done")
+yield 1 }
+let xs = [...gen()]
+len(log)`,
+			object.OBJ_NUMBER, float64(1)},
+		// a generator that is left early is closed, and its defers run
+		{`let log = []
+let gen = fn() { defer push(log, "done")
+yield 1
+yield 2 }
+let first = fn() { each x : gen() { return x } }
+first()
+len(log)`,
+			object.OBJ_NUMBER, float64(1)},
+		{`let log = []
+let gen = fn() { defer push(log, "done")
+yield 1
+yield 2 }
+try { each x : gen() { throw x } } catch { }
+len(log)`,
+			object.OBJ_NUMBER, float64(1)},
+		{`let log = []
+let gen = fn() { defer push(log, "done")
+yield 1
+yield 2 }
+let it = gen()
+it.next()
+it.close()
+len(log) == 1 and it.done()`,
+			object.OBJ_BOOL, true},
+		{`let gen = fn() { yield }
+gen().next() ?? 5`,
+			object.OBJ_NUMBER, float64(5)},
+		{`let gen = fn() { yield 1
+error("bad")?
+yield 2 }
+len([...gen()])`,
+			object.OBJ_NUMBER, float64(2)},
+		{`let gen = fn() { yield 1
+error("bad")? }
+let xs = [...gen()]
+xs[1].message`,
+			object.OBJ_STRING, "bad"},
+
+		{`let gen = fn() { yield 1
+[][1] }
+let sum = 0
+each n : gen() { sum = sum + n }`,
+			object.OBJ_ERROR, IndexOutOfBoundsError},
+		{`let gen = fn() { [][1]
+yield 1 }
+gen().next()`,
+			object.OBJ_ERROR, IndexOutOfBoundsError},
+		{`let gen = fn() { throw "boom"
+yield 1 }
+let xs = [...gen()]`,
+			object.OBJ_ERROR, ThrownError},
+		{`yield 1`,
+			object.OBJ_ERROR, IllegalOperationError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestIteratorProtocol(t *testing.T) {
+	counter := `let counter = fn(n) {
+let i = 0
+return {"next": fn() { i = i + 1
+return i }, "done": fn() { return i == n }}
+}
+`
+
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{counter + `let sum = 0
+each n : counter(3) { sum = sum + n }
+sum`,
+			object.OBJ_NUMBER, float64(6)},
+		{counter + `let xs = [...counter(4)]
+xs[3]`,
+			object.OBJ_NUMBER, float64(4)},
+		{counter + `let it = iter(counter(2))
+it.next()
+it.next()
+it.done()`,
+			object.OBJ_BOOL, true},
+		{counter + `let xs = [...counter(0)]
+len(xs)`,
+			object.OBJ_NUMBER, float64(0)},
+		{`let m = {"next": fn() { return 1 }, "done": fn() { return true }}
+len([...m])`,
+			object.OBJ_NUMBER, float64(0)},
+		// maps without the protocol iterate over their keys
+		{`let keys = [...{"next": 1, "done": 2}]
+len(keys)`,
+			object.OBJ_NUMBER, float64(2)},
+		{`let keys = [...{"next": fn() { return 1 }}]
+keys[0]`,
+			object.OBJ_STRING, "next"},
+
+		{`let m = {"next": fn() { return 1 }, "done": fn() { return 1 }}
+each x : m { }`,
+			object.OBJ_ERROR, object.TypeError},
+		{`let m = {"next": fn() { return [][1] }, "done": fn() { return false }}
+each x : m { }`,
+			object.OBJ_ERROR, IndexOutOfBoundsError},
+		{`let m = {"next": fn() { return 1 }, "done": fn() { throw "boom" }}
+each x : m { }`,
+			object.OBJ_ERROR, ThrownError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestIteratorProtocolErrorPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let m = {"next": fn() { return 1 }, "done": fn() { return 1 }}
+each x : m { }`,
+			"[2:10]"},
+		// next is called without arguments
+		{`let m = {"next": fn(a) { return a }, "done": fn() { return false }}
+each x : m { }`,
+			"[2:10]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			res, _ := testEvalProgram(tr, tt.input)
+
+			err, ok := res.(*object.ErrorObj)
+			tr.AssertTrue(ok, fmt.Sprintf("expected error, got=%v", res))
+			tr.AssertTrue(err.Token != nil, err.Error.Error())
+			tr.AssertEqual(err.Token.Pos.String(), tt.expected)
+		})
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{"[1, 2, 3] |> len()",
+			object.OBJ_NUMBER, float64(3)},
+		{"[1, 2, 3] |> len",
+			object.OBJ_NUMBER, float64(3)},
+		{"let sub = (a, b) => a - b; 10 |> sub(3)",
+			object.OBJ_NUMBER, float64(7)},
+		{"let double = x => x * 2; 1 |> double() |> double() |> str()",
+			object.OBJ_STRING, "4"},
+		{"let f = (a, b = 1) => a * b; 2 |> f(b: 5)",
+			object.OBJ_NUMBER, float64(10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestSpread(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{"fn f(a, b, c) { return a + b * c }\nf(...[1, 2, 3])",
+			object.OBJ_NUMBER, float64(7)},
+		{"fn f(a, b, c) { return a + b * c }\nf(1, ...[2, 3])",
+			object.OBJ_NUMBER, float64(7)},
+		{"fn f(...rest) { return len(rest) }\nf(...[1, 2], ...[3])",
+			object.OBJ_NUMBER, float64(3)},
+		{"let a = [1, 2]; let b = [...a, 3, ...a]; b[3] + len(b)",
+			object.OBJ_NUMBER, float64(6)},
+		{"len([...3])",
+			object.OBJ_NUMBER, float64(3)},
+		{`let s = [..."abc"]; s[2]`,
+			object.OBJ_STRING, "c"},
+		{`let m = {...{"a": 1, "b": 2}, "b": 3}; m["a"] + m["b"]`,
+			object.OBJ_NUMBER, float64(4)},
+		{`let m = {"b": 3, ...{"a": 1, "b": 2}}; m["b"]`,
+			object.OBJ_NUMBER, float64(2)},
+		{`let m = {...[["a", 1], ["b", 2]]}; m["b"]`,
+			object.OBJ_NUMBER, float64(2)},
+
+		{"[...true]",
+			object.OBJ_ERROR, SpreadError},
+		{`len(..."a", ...false)`,
+			object.OBJ_ERROR, SpreadError},
+		{"({...[1, 2]})",
+			object.OBJ_ERROR, SpreadError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedValue.(error)), res.Inspect())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestFunctionArguments(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		{"fn f(a, b) { return a - b }\nf(3, 1)",
+			object.OBJ_NUMBER, float64(2)},
+		{"fn f(a, b = 2) { return a * b }\nf(3)",
+			object.OBJ_NUMBER, float64(6)},
+		{"fn f(a, b = 2) { return a * b }\nf(3, 3)",
+			object.OBJ_NUMBER, float64(9)},
+		{"fn f(a, b = a + 1) { return b }\nf(1)",
+			object.OBJ_NUMBER, float64(2)},
+		{"fn f(a, b) { return a - b }\nf(b: 1, a: 3)",
+			object.OBJ_NUMBER, float64(2)},
+		{"fn f(a, b = 10, c = 100) { return a + b + c }\nf(1, c: 0)",
+			object.OBJ_NUMBER, float64(11)},
+		{"fn f(first, ...rest) { return len(rest) }\nf(1, 2, 3)",
+			object.OBJ_NUMBER, float64(2)},
+		{"fn f(first, ...rest) { return len(rest) }\nf(1)",
+			object.OBJ_NUMBER, float64(0)},
+		{"fn f(...rest) { return rest[1] }\nf(1, 2, 3)",
+			object.OBJ_NUMBER, float64(2)},
+
+		{"fn f(a, b) { return a }\nf(1)",
+			object.OBJ_ERROR, "function `f` is missing arguments for `b`"},
+		{"fn f(a, b, c) { return a }\nf(1)",
+			object.OBJ_ERROR, "function `f` is missing arguments for `b`, `c`"},
+		{"fn f(a) { return a }\nf(1, 2)",
+			object.OBJ_ERROR, "function `f` takes 1 arguments, got 2"},
+		{"fn f(a) { return a }\nf(b: 2)",
+			object.OBJ_ERROR, "function `f` has no parameter `b`"},
+		{"fn f(a) { return a }\nf(1, a: 2)",
+			object.OBJ_ERROR, "function `f` got multiple values for parameter `a`"},
+		{"let f = fn(a) { return a }; f()",
+			object.OBJ_ERROR, "function `f` is missing arguments for `a`"},
+		{"len(value: 2)",
+			object.OBJ_ERROR, "builtin `len` does not take named arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res, _ := testEvalProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), tt.expectedType, res.Inspect())
+			if tt.expectedType == object.OBJ_ERROR {
+				err := res.(*object.ErrorObj).Error
+				tr.AssertTrue(errors.Is(err, object.ArityError))
+				tr.AssertTrue(strings.Contains(err.Error(), tt.expectedValue.(string)), err.Error())
+				return
+			}
+
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
 // collection of tests for all builtin functions
 func TestBuiltinFunctions(t *testing.T) {
 	tests := []struct {
@@ -349,6 +1376,26 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`str(10)`, `10`},
 		{`str([1,2,3])`, `[1, 2, 3]`},
 		{`str("hello world")`, `"hello world"`},
+
+		{`sort([3, 1, 2])`, []float64{1, 2, 3}},
+		{`sort([])`, []float64{}},
+		{`sort(0..3, (a, b) => b - a)`, []float64{2, 1, 0}},
+		{`str(sort(["b", "c", "a"]))`, `["a", "b", "c"]`},
+		{`str(sort([[2, "a"], [1, "b"], [2, "c"]], (a, b) => a[0] - b[0]))`, `[[1, "b"], [2, "a"], [2, "c"]]`},
+		{`let xs = [2, 1]; sort(xs); xs[0]`, float64(2)},
+		{`sort([1, "a"])`, object.TypeError},
+		{`sort([1, 2], (a, b) => true)`, object.TypeError},
+		{`sort([1, 2], (a, b) => [][1])`, IndexOutOfBoundsError},
+		{`sort([1], 1)`, object.TypeError},
+
+		{`map([1, 2], x => x * 2)`, []float64{2, 4}},
+		{`map(1..4, x => x)`, []float64{1, 2, 3}},
+		{`map(["ab", "c"], len)`, []float64{2, 1}},
+		{`map([1])`, object.ArityError},
+
+		{`filter([1, 2, 3, 4], x => x > 2)`, []float64{3, 4}},
+		{`filter([1], x => 1)`, object.TypeError},
+		{`filter(1, x => true)`, []float64{0}},
 	}
 
 	for _, tt := range tests {
@@ -393,6 +1440,174 @@ func TestBuiltinFunctions(t *testing.T) {
 	}
 }
 
+func TestPrintOutput(t *testing.T) {
+	tr := tester.New(t, "")
+
+	// every interpreter prints to its own writer
+	var out, other bytes.Buffer
+	for _, w := range []*bytes.Buffer{&out, &other} {
+		program := parser.New(lexer.New("print 1, \"a\"\nprint [1]")).ParseProgram()
+		env := object.NewEnv(nil)
+		env.SetContext(NewInterpreter(w))
+		Eval(program, env)
+	}
+
+	tr.AssertEqual(out.String(), "1, \"a\"\n[1]\n")
+	tr.AssertEqual(other.String(), out.String())
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  object.ObjectType
+		expectedValue any
+	}{
+		// deep enough to exhaust the Go stack without tail calls
+		{"fn count(n, acc) {\n\tif n == 0 {\n\t\treturn acc\n\t}\n\treturn count(n - 1, acc + 1)\n}\ncount(1000000, 0)",
+			object.OBJ_NUMBER, 1000000.0},
+		{"fn even(n) {\n\tif n == 0 {\n\t\treturn true\n\t}\n\treturn odd(n - 1)\n}\nfn odd(n) {\n\tif n == 0 {\n\t\treturn false\n\t}\n\treturn even(n - 1)\n}\neven(100001)",
+			object.OBJ_BOOL, false},
+		{"fn f(n) {\n\tif n == 0 {\n\t\treturn \"done\"\n\t}\n\treturn f(n: n - 1)\n}\nf(10)",
+			object.OBJ_STRING, "done"},
+		{"fn f(xs) {\n\treturn len(xs)\n}\nf([1, 2, 3])",
+			object.OBJ_NUMBER, 3.0},
+		{"let f = n => if n == 0 { 0 } else { f(n - 1) }\nf(10)",
+			object.OBJ_NUMBER, 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			res := testEvalResolvedProgram(tr, tt.input)
+			testAssertType(tr, res, tt.expectedType, tt.expectedValue)
+		})
+	}
+}
+
+func TestTailCallErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError error
+	}{
+		{"fn f(n) {\n\tif n == 0 {\n\t\treturn 1 + []\n\t}\n\treturn f(n - 1)\n}\nf(10)",
+			IllegalOperationError},
+		{"fn f(a) {\n\treturn f()\n}\nf(1)",
+			object.ArityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			res := testEvalResolvedProgram(tr, tt.input)
+			err, ok := res.(*object.ErrorObj)
+			tr.AssertTrue(ok, fmt.Sprintf("expected error, got=%v", res))
+			tr.AssertTrue(errors.Is(err.Error, tt.expectedError), err.Error.Error())
+			tr.AssertNotNil(err.Token)
+		})
+	}
+}
+
+func TestStackOverflow(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedType object.ObjectType
+		expected     any
+	}{
+		{"fn f(n) {\n\treturn 1 + f(n)\n}\nf(0)",
+			object.OBJ_ERROR, StackOverflowError},
+		{"fn f() {\n\treturn map([1], x => f())\n}\nf()",
+			object.OBJ_ERROR, StackOverflowError},
+		{"fn f(n) {\n\treturn 1 + f(n)\n}\nlet kind = \"\"\ntry {\n\tf(0)\n} catch (e) {\n\tkind = e.kind\n}\nkind",
+			object.OBJ_STRING, "StackOverflow"},
+		// the depth is back to zero after an overflow
+		{"fn f(n) {\n\treturn 1 + f(n)\n}\ntry {\n\tf(0)\n} catch {}\nfn g(n) {\n\tif n == 0 {\n\t\treturn 0\n\t}\n\treturn 1 + g(n - 1)\n}\ng(99)",
+			object.OBJ_NUMBER, 99.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			in := NewInterpreter(os.Stdout)
+			in.SetMaxDepth(100)
+			res, _ := testEvalProgramWith(tr, in, tt.input)
+
+			if tt.expectedType == object.OBJ_ERROR {
+				err, ok := res.(*object.ErrorObj)
+				tr.AssertTrue(ok, fmt.Sprintf("expected error, got=%v", res))
+				tr.AssertTrue(errors.Is(err.Error, tt.expected.(error)), err.Error.Error())
+				// the calls are in the trace, and not in the message
+				tr.AssertTrue(!strings.Contains(err.Error.Error(), "at f"), err.Error.Error())
+				tr.AssertTrue(strings.HasPrefix(err.Trace[0].String(), "at f (2:"), err.Trace[0].String())
+				return
+			}
+			testAssertType(tr, res, tt.expectedType, tt.expected)
+		})
+	}
+}
+
+func TestConcurrentInterpreters(t *testing.T) {
+	// every interpreter has its own call stack and depth limit
+	program := "fn f(n) {\n\tif n == 0 {\n\t\treturn 0\n\t}\n\treturn 1 + f(n - 1)\n}\nf(500)"
+
+	var wg sync.WaitGroup
+	results := make([]object.Object, 8)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			in := NewInterpreter(os.Stdout)
+			if i%2 == 0 {
+				in.SetMaxDepth(100)
+			}
+			results[i], _ = testEvalProgramWith(tester.New(t, ""), in, program)
+		}()
+	}
+	wg.Wait()
+
+	tr := tester.New(t, "")
+	for i, res := range results {
+		if i%2 == 0 {
+			tr.AssertEqual(res.Type(), object.OBJ_ERROR, res.Inspect())
+			tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, StackOverflowError))
+		} else {
+			testAssertType(tr, res, object.OBJ_NUMBER, 500.0)
+		}
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"fn inner(x) {\n\treturn x + []\n}\nfn outer(x) {\n\tlet y = inner(x)\n\treturn y\n}\nouter(1)",
+			[]string{"at inner (5:15)", "at outer (8:6)"}},
+		{"fn f(x) {\n\tthrow x\n}\nmap([1], x => f(x))",
+			[]string{"at f (4:16)", "at <anonymous> (called by builtin)", "at map (4:4)"}},
+		{"len(1, 2)",
+			[]string{"at len (1:4)"}},
+		// no frames are added for errors outside of calls
+		{"1 + []",
+			[]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			res, _ := testEvalProgram(tr, tt.input)
+
+			err, ok := res.(*object.ErrorObj)
+			tr.AssertTrue(ok, fmt.Sprintf("expected error, got=%v", res))
+
+			frames := []string{}
+			for _, frame := range err.Trace {
+				frames = append(frames, frame.String())
+			}
+			tr.AssertEqual(strings.Join(frames, "\n"), strings.Join(tt.expected, "\n"))
+		})
+	}
+}
+
 func testAssertType(
 	tr *tester.Tester,
 	value object.Object,
@@ -418,6 +1633,13 @@ func testAssertType(
 func testEvalProgram(tr *tester.Tester, input string) (object.Object, *object.Environment) {
 	tr.T.Helper()
 
+	return testEvalProgramWith(tr, NewInterpreter(os.Stdout), input)
+}
+
+// like testEvalProgram, but the program is evaluated by in
+func testEvalProgramWith(tr *tester.Tester, in *Interpreter, input string) (object.Object, *object.Environment) {
+	tr.T.Helper()
+
 	l := lexer.New(input)
 	p := parser.New(l)
 
@@ -430,6 +1652,29 @@ func testEvalProgram(tr *tester.Tester, input string) (object.Object, *object.En
 	}
 
 	env := object.NewEnv(nil)
+	env.SetContext(in)
 
 	return Eval(program, env), env
 }
+
+// like testEvalProgram, but runs the resolver first, which marks the calls in tail position
+func testEvalResolvedProgram(tr *tester.Tester, input string) object.Object {
+	tr.T.Helper()
+
+	l := lexer.New(input)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if p.DidError() {
+		tr.T.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+	}
+
+	env := object.NewEnv(nil)
+	r := resolver.New(env)
+	r.Resolve(program)
+	if len(r.Errors) > 0 {
+		tr.T.Fatalf("resolver error\n%s", errors.Join(r.Errors...))
+	}
+
+	return Eval(program, env)
+}
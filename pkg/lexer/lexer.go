@@ -3,6 +3,7 @@ package lexer
 import (
 	"fmt"
 
+	"github.com/fredrikkvalvik/temp-lang/pkg/diagnostic"
 	"github.com/fredrikkvalvik/temp-lang/pkg/token"
 )
 
@@ -10,7 +11,9 @@ import (
 // ref: https://go.dev/doc/effective_go#semicolons
 
 type Lexer struct {
-	source string
+	// the text that is lexed. It is shared by the positions of every token,
+	// so they can be turned into line:col
+	src    *token.Source
 	tokens []token.Token
 
 	position     int  // position of the current lexeme
@@ -24,9 +27,13 @@ type Lexer struct {
 }
 
 func New(source string) *Lexer {
-	l := &Lexer{
+	return NewFromSource(token.NewSource("", source))
+}
 
-		source: source,
+// NewFromSource returns a lexer for a file, the positions of the tokens refer to src
+func NewFromSource(src *token.Source) *Lexer {
+	l := &Lexer{
+		src:    src,
 		tokens: []token.Token{},
 
 		line: 1,
@@ -47,32 +54,7 @@ func (l *Lexer) DidError() bool {
 
 // used for error messages
 func (l *Lexer) GetTokenPosition(tok *token.Token) (int, int) {
-	offset := tok.Pos
-
-	line, col := l.getTokenPostionFromOffset(offset)
-	return line, col
-}
-
-func (l *Lexer) getTokenPostionFromOffset(pos token.Pos) (int, int) {
-	col := 1
-	line := 1
-
-	position := 0
-	// increment col for each loop.
-	// when we see a '\n', reset col and keep moving
-	// return col at end
-	for position < pos.Start {
-		if l.source[position] == '\n' {
-			line += 1
-			col = 1
-		} else {
-			col += 1
-		}
-
-		position += 1
-	}
-
-	return line, col
+	return tok.Pos.Position()
 }
 
 // pull tokens when needed
@@ -114,15 +96,52 @@ REDO:
 	case ',':
 		tok = l.getToken(token.COMMA, string(l.ch))
 	case '.':
-		tok = l.getToken(token.DOT, string(l.ch))
+		if l.peek() == '.' && l.peekNext() == '.' {
+			l.advance()
+			l.advance()
+			tok = l.getToken(token.ELLIPSIS, "...")
+		} else if l.peek() == '.' && l.peekNext() == '=' {
+			l.advance()
+			l.advance()
+			tok = l.getToken(token.RANGE_INCL, "..=")
+		} else if l.peek() == '.' {
+			l.advance()
+			tok = l.getToken(token.RANGE, "..")
+		} else {
+			tok = l.getToken(token.DOT, string(l.ch))
+		}
 	case ':':
 		tok = l.getToken(token.COLON, string(l.ch))
+	case '?':
+		switch {
+		case l.peek() == '.':
+			l.advance()
+			tok = l.getToken(token.OPTIONAL_DOT, "?.")
+		// `c ? [1] : [2]` and `c ?[1] : [2]` are ternaries, optional
+		// indexing is written right after the value, `xs?[1]`
+		case l.peek() == '[' && l.followsValue():
+			l.advance()
+			tok = l.getToken(token.OPTIONAL_LBRACKET, "?[")
+		case l.peek() == '?':
+			l.advance()
+			tok = l.getToken(token.NULLISH, "??")
+		default:
+			tok = l.getToken(token.QUESTION, string(l.ch))
+		}
 	case ';':
 		tok = l.getToken(token.SEMICOLON, string(l.ch))
 	case '<':
 		tok = l.getToken(token.LT, string(l.ch))
 	case '>':
 		tok = l.getToken(token.GT, string(l.ch))
+	case '|':
+		if l.peek() == '>' {
+			l.advance()
+			tok = l.getToken(token.PIPE, "|>")
+		} else {
+			tok = l.getToken(token.ILLEGAL, string(l.ch))
+			l.error(&tok, fmt.Errorf("Unexpected character"))
+		}
 
 	case '/':
 		if l.peek() == '/' {
@@ -142,6 +161,9 @@ REDO:
 		if l.peek() == '=' {
 			l.advance()
 			tok = l.getToken(token.EQ, "==")
+		} else if l.peek() == '>' {
+			l.advance()
+			tok = l.getToken(token.ARROW, "=>")
 		} else {
 			tok = l.getToken(token.ASSIGN, string(l.ch))
 		}
@@ -178,14 +200,14 @@ REDO:
 			lexeme, err := l.readNumber()
 			if err != nil {
 				tok = l.getToken(token.ILLEGAL, lexeme)
-				l.error(err)
+				l.error(&tok, err)
 				break
 			}
 			tok = l.getToken(token.NUMBER, lexeme)
 
 		} else {
 			tok = l.getToken(token.ILLEGAL, string(l.ch))
-			l.error(fmt.Errorf("Unexpected character"))
+			l.error(&tok, fmt.Errorf("Unexpected character"))
 		}
 	}
 
@@ -198,7 +220,7 @@ func (l *Lexer) getToken(t token.TokenType, lexeme string) token.Token {
 
 		Type:   t,
 		Lexeme: lexeme,
-		Pos:    token.Pos{Src: &l.source, Start: l.position, End: l.readPosition},
+		Pos:    token.Pos{Src: l.src, Start: l.position, End: l.readPosition},
 	}
 
 	return tok
@@ -208,7 +230,7 @@ func (l *Lexer) advance() {
 	if l.atEnd() {
 		l.ch = 0
 	} else {
-		l.ch = l.source[l.readPosition]
+		l.ch = l.src.Text[l.readPosition]
 		l.position = l.readPosition
 		l.readPosition += 1
 	}
@@ -219,11 +241,20 @@ func (l *Lexer) peek() byte {
 		return 0
 	}
 
-	return l.source[l.readPosition]
+	return l.src.Text[l.readPosition]
+}
+
+// returns the character after the peeked character
+func (l *Lexer) peekNext() byte {
+	if l.readPosition+1 >= len(l.src.Text) {
+		return 0
+	}
+
+	return l.src.Text[l.readPosition+1]
 }
 
 func (l *Lexer) atEnd() bool {
-	return l.readPosition >= len(l.source)
+	return l.readPosition >= len(l.src.Text)
 }
 
 func (l *Lexer) whitespace() *token.Token {
@@ -242,7 +273,7 @@ func (l *Lexer) whitespace() *token.Token {
 
 // handles newline for linenumber and returns a semicolon if the conditions are correct
 func (l *Lexer) newline() *token.Token {
-	if l.newlineIsTerminal() {
+	if l.newlineIsTerminal() && !l.nextLineIsPipe() {
 		tok := l.getToken(token.SEMICOLON, string(l.ch))
 		l.line += 1
 		l.advance()
@@ -255,6 +286,34 @@ func (l *Lexer) newline() *token.Token {
 	}
 }
 
+// reports if the next non-blank line starts with `|>`, so a pipeline
+// can be continued on the next line without inserting a ';'
+func (l *Lexer) nextLineIsPipe() bool {
+	pos := l.readPosition
+	for pos < len(l.src.Text) {
+		switch l.src.Text[pos] {
+		case ' ', '\t', '\r', '\n':
+			pos++
+		default:
+			return pos+1 < len(l.src.Text) && l.src.Text[pos] == '|' && l.src.Text[pos+1] == '>'
+		}
+	}
+	return false
+}
+
+// reports if the current character comes right after the previous token,
+// without any whitespace in between
+func (l *Lexer) followsValue() bool {
+	if l.position == 0 {
+		return false
+	}
+	switch l.src.Text[l.position-1] {
+	case ' ', '\t', '\r', '\n':
+		return false
+	}
+	return true
+}
+
 // returns the lexeme and the literal value of the string
 func (l *Lexer) readString() string {
 	for {
@@ -266,7 +325,7 @@ func (l *Lexer) readString() string {
 			break
 		}
 	}
-	lexeme := l.source[l.position:l.readPosition]
+	lexeme := l.src.Text[l.position:l.readPosition]
 
 	return lexeme
 }
@@ -281,20 +340,15 @@ func (l *Lexer) readNumber() (string, error) {
 		l.readPosition += 1
 		continue
 	}
-	if l.peek() == '.' {
+	// `0..10` is a range, not a decimal
+	if l.peek() == '.' && l.peekNext() != '.' {
 		// consume .
 		l.readPosition += 1
 
 		// if the next char is not a number, then the token is invalid
+		// the error is reported at the start of the number
 		if !isDigit(l.peek()) {
-			line, col := l.getTokenPostionFromOffset(token.Pos{Src: &l.source, Start: l.position, End: l.readPosition - 1})
-
-			err := fmt.Errorf("[%d:%d]: expected digit, got=%s\n",
-				line,
-				col,
-				string(l.peek()))
-			return "", err
-
+			return "", fmt.Errorf("expected digit, got=%s", string(l.peek()))
 		}
 		// parse decimal digits
 		for {
@@ -307,7 +361,7 @@ func (l *Lexer) readNumber() (string, error) {
 		}
 	}
 
-	lexeme := l.source[l.position:l.readPosition]
+	lexeme := l.src.Text[l.position:l.readPosition]
 
 	return lexeme, nil
 }
@@ -317,7 +371,7 @@ func (l *Lexer) readIdentifier() string {
 		l.readPosition += 1
 	}
 
-	return l.source[l.position:l.readPosition]
+	return l.src.Text[l.position:l.readPosition]
 }
 
 // returns true if the previous token followed by a new line satisifies automatic insertion of semicolon
@@ -343,13 +397,18 @@ func (l *Lexer) newlineIsTerminal() bool {
 		return true
 	case token.RETURN:
 		return true
+	case token.YIELD:
+		return true
+	// a `?` at the end of a line is the postfix `value?`
+	case token.QUESTION:
+		return true
 	}
 
 	return false
 }
 
-func (l *Lexer) error(err error) {
-	l.errors = append(l.errors, err)
+func (l *Lexer) error(tok *token.Token, err error) {
+	l.errors = append(l.errors, diagnostic.New(tok.Pos, err))
 }
 
 func isDigit(ch byte) bool {
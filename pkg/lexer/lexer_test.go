@@ -32,6 +32,11 @@ true and false
 true or false
 "foobar"
 "foo bar"
+xs
+	|> f()
+a?.b?["c"] ?? d ?[e] : f
+f()? + g()?
+0..10 1..=2.5
 [1, 2];
 {"foo": "bar"}
 `
@@ -123,6 +128,44 @@ true or false
 		{token.SEMICOLON, "\n"},
 		{token.STRING, "\"foo bar\""},
 		{token.SEMICOLON, "\n"},
+		{token.IDENT, "xs"},
+		{token.PIPE, "|>"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "a"},
+		{token.OPTIONAL_DOT, "?."},
+		{token.IDENT, "b"},
+		{token.OPTIONAL_LBRACKET, "?["},
+		{token.STRING, "\"c\""},
+		{token.RBRACKET, "]"},
+		{token.NULLISH, "??"},
+		{token.IDENT, "d"},
+		{token.QUESTION, "?"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "e"},
+		{token.RBRACKET, "]"},
+		{token.COLON, ":"},
+		{token.IDENT, "f"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.QUESTION, "?"},
+		{token.PLUS, "+"},
+		{token.IDENT, "g"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.QUESTION, "?"},
+		{token.SEMICOLON, "\n"},
+		{token.NUMBER, "0"},
+		{token.RANGE, ".."},
+		{token.NUMBER, "10"},
+		{token.NUMBER, "1"},
+		{token.RANGE_INCL, "..="},
+		{token.NUMBER, "2.5"},
+		{token.SEMICOLON, "\n"},
 		{token.LBRACKET, "["},
 		{token.NUMBER, "1"},
 		{token.COMMA, ","},
@@ -1,13 +1,17 @@
 package object
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 )
 
 var (
-	ArityError = errors.New("wrong number of args")
-	TypeError  = errors.New("invalid type")
+	ArityError  = errors.New("wrong number of args")
+	TypeError   = errors.New("invalid type")
+	FrozenError = errors.New("value is frozen")
 )
 
 type ErrorBuf[T interface{}] struct {
@@ -36,6 +40,14 @@ func CheckObjectType(obj Object, typ ObjectType) *ErrorObj {
 	return nil
 }
 
+// util for builtin functions to return an error if check evaluates to false. return nil on ok
+func CheckCallable(obj Object) *ErrorObj {
+	if !isCallable(obj) {
+		return &ErrorObj{Error: fmt.Errorf("%w: expected function, got %s", TypeError, obj.Type())}
+	}
+	return nil
+}
+
 // util for builtin functions to return an error if check evaluates to false. return nil on ok
 func CheckIntegral(n float64) *ErrorObj {
 	if !isIntegral(n) {
@@ -44,6 +56,82 @@ func CheckIntegral(n float64) *ErrorObj {
 	return nil
 }
 
+// util for builtin functions to return an error if obj is a frozen list or map. return nil on ok
+func CheckNotFrozen(obj Object) *ErrorObj {
+	frozen := false
+	switch obj := obj.(type) {
+	case *ListObj:
+		frozen = obj.Frozen
+	case *MapObj:
+		frozen = obj.Frozen
+	}
+
+	if frozen {
+		return &ErrorObj{Error: fmt.Errorf("%w: can't modify %s", FrozenError, obj.Inspect())}
+	}
+	return nil
+}
+
+// Freeze marks obj, and every list and map reachable from it, as frozen
+func Freeze(obj Object) {
+	switch obj := obj.(type) {
+	case *ListObj:
+		// checking Frozen first also stops lists that contain themselves
+		if obj.Frozen {
+			return
+		}
+		obj.Frozen = true
+		for _, value := range obj.Values {
+			Freeze(value)
+		}
+
+	case *MapObj:
+		if obj.Frozen {
+			return
+		}
+		obj.Frozen = true
+		for _, pair := range obj.Pairs {
+			Freeze(pair.Key)
+			Freeze(pair.Value)
+		}
+	}
+}
+
+// Arity: 1
+//
+// Arg0: any
+//
+// freeze makes a list or map, and every list and map inside it, immutable.
+// returns the argument
+func FreezeBuiltin(args ...Object) Object {
+	if err := CheckArity(args, 1); err != nil {
+		return err
+	}
+
+	Freeze(args[0])
+	return args[0]
+}
+
+// Arity: 1
+//
+// Arg0: any
+//
+// error creates an error value with the kind "Error". A string argument is the
+// message, other values are inspected. The error is a value, and does not
+// stop the program until it is thrown
+func ErrorBuiltin(args ...Object) Object {
+	if err := CheckArity(args, 1); err != nil {
+		return err
+	}
+
+	message := args[0].Inspect()
+	if str, ok := args[0].(*StringObj); ok {
+		message = str.Value
+	}
+
+	return &ErrorValueObj{Kind: "Error", Message: message, Value: args[0]}
+}
+
 // Arity: 1
 //
 // Arg0: list | map | string
@@ -85,6 +173,10 @@ func PushBuiltin(args ...Object) Object {
 		return &ErrorObj{Error: fmt.Errorf("%w: expected list, got=%s", TypeError, list.Type())}
 	}
 
+	if err := CheckNotFrozen(list); err != nil {
+		return err
+	}
+
 	items := args[1:]
 	list.(*ListObj).Values = append(list.(*ListObj).Values, items...)
 
@@ -102,6 +194,7 @@ func PopBuiltin(args ...Object) Object {
 
 	ebuf.Run(func() *ErrorObj { return CheckArity(args, 1) })
 	ebuf.Run(func() *ErrorObj { return CheckObjectType(args[0], OBJ_LIST) })
+	ebuf.Run(func() *ErrorObj { return CheckNotFrozen(args[0]) })
 	if ebuf.Err != nil {
 		return ebuf.Err
 	}
@@ -155,15 +248,24 @@ func RangeBuiltin(args ...Object) Object {
 		return ebuf.Err
 	}
 
-	startObj := args[0].(*NumberObj)
-	endObj := args[1].(*NumberObj)
-	stepObj := args[2].(*NumberObj)
+	return NewRange(
+		args[0].(*NumberObj).Value,
+		args[1].(*NumberObj).Value,
+		args[2].(*NumberObj).Value,
+	)
+}
 
-	ebuf.Run(func() *ErrorObj { return CheckIntegral(startObj.Value) })
-	ebuf.Run(func() *ErrorObj { return CheckIntegral(endObj.Value) })
-	ebuf.Run(func() *ErrorObj { return CheckIntegral(stepObj.Value) })
+// NewRange returns an iterator from start up to, but not including, end.
+// step must be a positive whole number. When start is greater than end
+// the range counts down
+func NewRange(start, end, step float64) Object {
+	var ebuf ErrorBuf[ErrorObj]
+
+	ebuf.Run(func() *ErrorObj { return CheckIntegral(start) })
+	ebuf.Run(func() *ErrorObj { return CheckIntegral(end) })
+	ebuf.Run(func() *ErrorObj { return CheckIntegral(step) })
 	ebuf.Run(func() *ErrorObj {
-		if stepObj.Value <= 0 {
+		if step <= 0 {
 			return &ErrorObj{Error: fmt.Errorf("%w: step value must be a none-zero, positive number", TypeError)}
 		}
 		return nil
@@ -173,17 +275,13 @@ func RangeBuiltin(args ...Object) Object {
 		return ebuf.Err
 	}
 
-	start := int(startObj.Value)
-	end := int(endObj.Value)
-	step := int(stepObj.Value)
 	isNegative := start > end
-
 	if isNegative {
 		step = -step
 	}
 
 	return &IteratorObj{
-		Iterator: newRangeIterator(start, end, step),
+		Iterator: newRangeIterator(int(start), int(end), int(step)),
 	}
 }
 
@@ -192,13 +290,13 @@ func RangeBuiltin(args ...Object) Object {
 // Arg0: any
 //
 // returns an iterator based on the argument
-func IterBuiltin(args ...Object) Object {
+func IterBuiltin(ctx Context, args ...Object) Object {
 	if err := CheckArity(args, 1); err != nil {
 		return err
 	}
 	arg := args[0]
 
-	iterator, err := NewIterator(arg)
+	iterator, err := NewIterator(ctx, arg)
 	if err != nil {
 		return err
 	}
@@ -207,3 +305,156 @@ func IterBuiltin(args ...Object) Object {
 		Iterator: iterator,
 	}
 }
+
+// Arity: 1 | 2
+//
+// Arg0: iterable, Arg1: function
+//
+// sort returns a sorted list of the items. Without a comparator, numbers and
+// strings are sorted in ascending order. cmp(a, b) returns a negative number
+// when a comes before b, a positive number when b comes before a, and 0 when
+// they are equal. The sort is stable
+func SortBuiltin(ctx Context, args ...Object) Object {
+	if len(args) != 1 && len(args) != 2 {
+		return &ErrorObj{Error: fmt.Errorf("%w: expected 1 or 2 args, got %d", ArityError, len(args))}
+	}
+	items, errObj := collect(ctx, args[0])
+	if errObj != nil {
+		return errObj
+	}
+
+	compare := compareObjects
+	if len(args) == 2 {
+		if err := CheckCallable(args[1]); err != nil {
+			return err
+		}
+		compare = func(a, b Object) (int, *ErrorObj) {
+			res := ctx.Call(args[1], a, b)
+			if res.Type() == OBJ_ERROR {
+				return 0, res.(*ErrorObj)
+			}
+			if err := CheckObjectType(res, OBJ_NUMBER); err != nil {
+				return 0, err
+			}
+			return cmp.Compare(res.(*NumberObj).Value, 0), nil
+		}
+	}
+
+	// the first error stops the comparisons, and is returned when the sort is done
+	var sortErr *ErrorObj
+	slices.SortStableFunc(items, func(a, b Object) int {
+		if sortErr != nil {
+			return 0
+		}
+		n, err := compare(a, b)
+		if err != nil {
+			sortErr = err
+		}
+		return n
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+
+	return &ListObj{Values: items}
+}
+
+// Arity: 2
+//
+// Arg0: iterable, Arg1: function
+//
+// map returns a list of fn(item) for every item
+func MapBuiltin(ctx Context, args ...Object) Object {
+	var ebuf ErrorBuf[ErrorObj]
+
+	ebuf.Run(func() *ErrorObj { return CheckArity(args, 2) })
+	ebuf.Run(func() *ErrorObj { return CheckCallable(args[1]) })
+	if ebuf.Err != nil {
+		return ebuf.Err
+	}
+
+	items, err := collect(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		res := ctx.Call(args[1], item)
+		if res.Type() == OBJ_ERROR {
+			return res
+		}
+		items[i] = res
+	}
+
+	return &ListObj{Values: items}
+}
+
+// Arity: 2
+//
+// Arg0: iterable, Arg1: function
+//
+// filter returns a list of the items where fn(item) is true
+func FilterBuiltin(ctx Context, args ...Object) Object {
+	var ebuf ErrorBuf[ErrorObj]
+
+	ebuf.Run(func() *ErrorObj { return CheckArity(args, 2) })
+	ebuf.Run(func() *ErrorObj { return CheckCallable(args[1]) })
+	if ebuf.Err != nil {
+		return ebuf.Err
+	}
+
+	items, err := collect(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	kept := []Object{}
+	for _, item := range items {
+		res := ctx.Call(args[1], item)
+		if res.Type() == OBJ_ERROR {
+			return res
+		}
+		if err := CheckObjectType(res, OBJ_BOOL); err != nil {
+			return err
+		}
+		if res.(*BooleanObj).Value {
+			kept = append(kept, item)
+		}
+	}
+
+	return &ListObj{Values: kept}
+}
+
+// returns every item of iterable in a new slice
+func collect(ctx Context, iterable Object) ([]Object, *ErrorObj) {
+	iterator, err := NewIterator(ctx, iterable)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIterator(iterator)
+
+	items := []Object{}
+	for !iterator.Done() {
+		item := iterator.Next()
+		if item.Type() == OBJ_ERROR {
+			return nil, item.(*ErrorObj)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// the order used by sort when there is no comparator
+func compareObjects(a, b Object) (int, *ErrorObj) {
+	switch a := a.(type) {
+	case *NumberObj:
+		if b, ok := b.(*NumberObj); ok {
+			return cmp.Compare(a.Value, b.Value), nil
+		}
+	case *StringObj:
+		if b, ok := b.(*StringObj); ok {
+			return strings.Compare(a.Value, b.Value), nil
+		}
+	}
+	return 0, &ErrorObj{Error: fmt.Errorf("%w: can't compare %s and %s", TypeError, a.Type(), b.Type())}
+}
@@ -0,0 +1,17 @@
+package object
+
+import "io"
+
+// Context gives builtins access to the interpreter that calls them
+type Context interface {
+	// Call calls fn, which can be any callable object, with args. An error is
+	// returned as an *ErrorObj that keeps the position where it happened
+	Call(fn Object, args ...Object) Object
+
+	// Out is the writer that `print` writes to
+	Out() io.Writer
+}
+
+// ContextBuiltinFn is a builtin that needs the interpreter, like a builtin
+// that takes a callback
+type ContextBuiltinFn func(ctx Context, args ...Object) Object
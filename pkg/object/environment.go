@@ -11,6 +11,12 @@ type Environment struct {
 
 	// lookup table for the variables declared in the Environment scope
 	vars map[string]Object
+
+	// set on the scope of a function call, nil for every other scope
+	frame *Frame
+
+	// the interpreter evaluating the program, usually set on the global scope
+	ctx Context
 }
 
 func NewEnv(parent *Environment) *Environment {
@@ -20,6 +26,38 @@ func NewEnv(parent *Environment) *Environment {
 	}
 }
 
+// creates the scope for a function call
+func NewFunctionEnv(parent *Environment, frame *Frame) *Environment {
+	env := NewEnv(parent)
+	env.frame = frame
+	return env
+}
+
+// returns the frame of the closest function call, or nil in global scope
+func (e *Environment) Frame() *Frame {
+	for env := e; env != nil; env = env.parent {
+		if env.frame != nil {
+			return env.frame
+		}
+	}
+	return nil
+}
+
+// returns the interpreter of the closest scope that has one, or nil
+func (e *Environment) Context() Context {
+	for env := e; env != nil; env = env.parent {
+		if env.ctx != nil {
+			return env.ctx
+		}
+	}
+	return nil
+}
+
+// SetContext sets the interpreter that evaluates the scope and its inner scopes
+func (e *Environment) SetContext(ctx Context) {
+	e.ctx = ctx
+}
+
 func (e *Environment) DeclareVar(key string, value Object) Object {
 	if e.varInScope(key) {
 		return illegalDeclarationError(key)
@@ -0,0 +1,23 @@
+package object
+
+import "github.com/fredrikkvalvik/temp-lang/pkg/ast"
+
+// Frame holds the state of a single function call
+type Frame struct {
+	// deferred expressions, in the order they were deferred
+	Defers []Deferred
+
+	// passes a value to the consumer of a generator. nil when the call is not a
+	// generator. Returns false when the consumer has stopped
+	Yield func(Object) bool
+}
+
+// Deferred is an expression that is evaluated in Env when the function returns
+type Deferred struct {
+	Expr ast.Expr
+	Env  *Environment
+}
+
+func (f *Frame) Defer(expr ast.Expr, env *Environment) {
+	f.Defers = append(f.Defers, Deferred{Expr: expr, Env: env})
+}
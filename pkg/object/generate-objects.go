@@ -55,9 +55,11 @@ var objects = []template{
 		name: "FnLiteral",
 		typ:  object.OBJ_FUNCTION_LITERAL,
 		props: []keyVal{
-			{"Parameters", "[]*ast.IdentifierExpr"},
+			{"Name", "string"},
+			{"Parameters", "[]*ast.Parameter"},
 			{"Body", "*ast.BlockStmt"},
 			{"Env", "*Environment"},
+			{"Generator", "bool"},
 		},
 	},
 	{
@@ -72,6 +74,7 @@ var objects = []template{
 		typ:  object.OBJ_LIST,
 		props: []keyVal{
 			{"Values", "[]Object"},
+			{"Frozen", "bool"},
 		},
 	},
 	{
@@ -79,6 +82,7 @@ var objects = []template{
 		typ:  object.OBJ_MAP,
 		props: []keyVal{
 			{"Pairs", "map[HashKey]KeyValuePair"},
+			{"Frozen", "bool"},
 		},
 	},
 	{
@@ -95,6 +99,7 @@ var objects = []template{
 		typ:  object.OBJ_BUILTIN,
 		props: []keyVal{
 			{"Fn", "BuiltinFn"},
+			{"ContextFn", "ContextBuiltinFn"}, // called instead of Fn when set
 			{"Name", "string"},
 		},
 	},
@@ -111,6 +116,18 @@ var objects = []template{
 		props: []keyVal{
 			{"Error", "error"},
 			{"Token", "*token.Token"},
+			{"Value", "Object"},       // the value passed to `throw`, nil for runtime errors
+			{"Trace", "[]TraceFrame"}, // the calls the error unwound through, innermost first
+		},
+	},
+	{
+		name: "ErrorValue",
+		typ:  object.OBJ_ERROR_VALUE,
+		props: []keyVal{
+			{"Kind", "string"},
+			{"Message", "string"},
+			{"Value", "Object"}, // the thrown value, nil when the error did not come from `throw`
+			{"Token", "*token.Token"},
 		},
 	},
 }
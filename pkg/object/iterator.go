@@ -3,6 +3,7 @@ package object
 
 import (
 	"fmt"
+	"iter"
 	"maps"
 	"slices"
 	"strings"
@@ -16,6 +17,9 @@ const (
 	ITER_LIST
 	ITER_MAP
 	ITER_RANGE
+	ITER_GENERATOR
+	ITER_PROTOCOL
+	ITER_LAZY // iterators from the iter std module
 )
 
 // return true while iterator is returning items. return false when end of loop is finisjed
@@ -25,7 +29,24 @@ type Iterator interface {
 	Type() IteratorType
 }
 
-func NewIterator(iterable Object) (Iterator, *ErrorObj) {
+// Closer is implemented by iterators that hold on to something until they are
+// done, like the suspended body of a generator. Close lets it go when the
+// iterator is dropped before it is done
+type Closer interface {
+	Close()
+}
+
+// CloseIterator closes it when it is a Closer. Closing an iterator that is
+// done or already closed does nothing
+func CloseIterator(it Iterator) {
+	if closer, ok := it.(Closer); ok {
+		closer.Close()
+	}
+}
+
+// returns an iterator over iterable. ctx is used by iterators that call
+// functions defined in a script
+func NewIterator(ctx Context, iterable Object) (Iterator, *ErrorObj) {
 	switch it := iterable.(type) {
 	case *StringObj:
 		return newStringIterator(it), nil
@@ -37,6 +58,10 @@ func NewIterator(iterable Object) (Iterator, *ErrorObj) {
 	case *ListObj:
 		return newListIterator(it), nil
 	case *MapObj:
+		// maps with `next` and `done` functions implement the iterator protocol
+		if iterator, ok := newProtocolIterator(ctx, it); ok {
+			return iterator, nil
+		}
 		return newMapIterator(it), nil
 
 	// used for arbitrary iterators
@@ -186,3 +211,124 @@ func (ri *RangeIter) Done() bool {
 		return ri.index >= int(ri.end)
 	}
 }
+
+// Generator
+
+// GeneratorIter pulls values from a function body that is suspended at each
+// `yield`. The next value is computed ahead, so Done can tell if the body
+// will yield again
+type GeneratorIter struct {
+	next func() (Object, bool)
+	stop func()
+
+	value    Object // the value returned by the next call to Next
+	buffered bool   // value holds a value that has not been returned yet
+	done     bool
+}
+
+func NewGenerator(seq iter.Seq[Object]) *GeneratorIter {
+	next, stop := iter.Pull(seq)
+	return &GeneratorIter{next: next, stop: stop}
+}
+
+func (gi *GeneratorIter) Type() IteratorType { return ITER_GENERATOR }
+
+func (gi *GeneratorIter) Next() Object {
+	gi.fill()
+	if gi.done {
+		return nil
+	}
+	gi.buffered = false
+	return gi.value
+}
+
+func (gi *GeneratorIter) Done() bool {
+	gi.fill()
+	return gi.done
+}
+
+// Close ends the body where it is suspended, as if the `yield` returned, so
+// its deferred expressions run
+func (gi *GeneratorIter) Close() {
+	if gi.done {
+		return
+	}
+	gi.done = true
+	gi.buffered = false
+	gi.value = nil
+	gi.stop()
+}
+
+// resumes the body until it yields a value or returns
+func (gi *GeneratorIter) fill() {
+	if gi.buffered || gi.done {
+		return
+	}
+
+	value, ok := gi.next()
+	if !ok {
+		gi.done = true
+		gi.stop()
+		return
+	}
+	gi.value = value
+	gi.buffered = true
+}
+
+// Protocol
+
+// ProtocolIter iterates a map that has a `next` and a `done` function.
+// The functions are called without arguments
+type ProtocolIter struct {
+	ctx  Context
+	next Object
+	done Object
+
+	err Object // error from calling done, returned by the next call to Next
+}
+
+func newProtocolIterator(ctx Context, m *MapObj) (*ProtocolIter, bool) {
+	next, ok := m.Pairs[(&StringObj{Value: "next"}).HashKey()]
+	if !ok || !isCallable(next.Value) {
+		return nil, false
+	}
+	done, ok := m.Pairs[(&StringObj{Value: "done"}).HashKey()]
+	if !ok || !isCallable(done.Value) {
+		return nil, false
+	}
+
+	return &ProtocolIter{ctx: ctx, next: next.Value, done: done.Value}, true
+}
+
+func (pi *ProtocolIter) Type() IteratorType { return ITER_PROTOCOL }
+
+func (pi *ProtocolIter) Next() Object {
+	if pi.err != nil {
+		return pi.err
+	}
+
+	return pi.ctx.Call(pi.next)
+}
+
+// Done can't return an error, so it reports false when done() fails, and
+// the error is returned by Next
+func (pi *ProtocolIter) Done() bool {
+	if pi.err != nil {
+		return false
+	}
+
+	res := pi.ctx.Call(pi.done)
+	switch res := res.(type) {
+	case *BooleanObj:
+		return res.Value
+	case *ErrorObj:
+		pi.err = res
+	default:
+		pi.err = &ErrorObj{Error: fmt.Errorf("%w: done() must return a boolean, got %s", TypeError, res.Inspect())}
+	}
+	return false
+}
+
+func isCallable(obj Object) bool {
+	return obj.Type() == OBJ_FUNCTION_LITERAL || obj.Type() == OBJ_BUILTIN
+}
@@ -13,11 +13,14 @@ func _() {
 	_ = x[ITER_LIST-2]
 	_ = x[ITER_MAP-3]
 	_ = x[ITER_RANGE-4]
+	_ = x[ITER_GENERATOR-5]
+	_ = x[ITER_PROTOCOL-6]
+	_ = x[ITER_LAZY-7]
 }
 
-const _IteratorType_name = "ITER_NUMBERITER_STRINGITER_LISTITER_MAPITER_RANGE"
+const _IteratorType_name = "ITER_NUMBERITER_STRINGITER_LISTITER_MAPITER_RANGEITER_GENERATORITER_PROTOCOLITER_LAZY"
 
-var _IteratorType_index = [...]uint8{0, 11, 22, 31, 39, 49}
+var _IteratorType_index = [...]uint8{0, 11, 22, 31, 39, 49, 63, 76, 85}
 
 func (i IteratorType) String() string {
 	if i < 0 || i >= IteratorType(len(_IteratorType_index)-1) {
@@ -6,6 +6,9 @@ package object
 import (
 	"fmt"
 	"strings"
+
+	"github.com/fredrikkvalvik/temp-lang/pkg/diagnostic"
+	"github.com/fredrikkvalvik/temp-lang/pkg/token"
 )
 
 // object represents runtime values.
@@ -36,6 +39,8 @@ const (
 	OBJ_ITERATOR         // a wrapper for returning iterators from builtin functions
 	OBJ_MODULE           // Module is an object that holds the references to a unit of code that has been imported by a caller
 	OBJ_ERROR            // runtime error
+	OBJ_ERROR_VALUE      // an error that has been caught, and can be used as a value
+	OBJ_TAIL_CALL        // internal type for calls in tail position
 )
 
 type ModuleType int
@@ -106,11 +111,21 @@ func (b *BuiltinObj) Inspect() string { return fmt.Sprintf("[builtin %s]", b.Nam
 
 func (b *ModuleObj) Inspect() string { return fmt.Sprintf("[Module %s]", b.Name) }
 
+func (b *ErrorValueObj) Inspect() string { return fmt.Sprintf("[%s: %s]", b.Kind, b.Message) }
+
 func (b *ErrorObj) Inspect() string {
 	if b.Token != nil {
-		line, col := b.Token.Pos.Position()
-		return fmt.Sprintf("[%d:%d] %s", line, col, b.Error.Error())
+		return fmt.Sprintf("%s %s", b.Token.Pos, b.Error.Error())
 	} else {
 		return b.Error.Error()
 	}
 }
+
+// Diagnostic returns the error as a diagnostic at the token it happened at
+func (b *ErrorObj) Diagnostic() *diagnostic.Diagnostic {
+	var span token.Pos
+	if b.Token != nil {
+		span = b.Token.Pos
+	}
+	return diagnostic.New(span, b.Error)
+}
@@ -2,6 +2,7 @@ package object
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/fredrikkvalvik/temp-lang/pkg/tester"
@@ -66,3 +67,36 @@ func TestHashes(t *testing.T) {
 		})
 	}
 }
+
+func TestStackTrace(t *testing.T) {
+	tr := tester.New(t, "")
+
+	err := &ErrorObj{}
+	for i := range maxTraceFrames + 5 {
+		err.Trace = append(err.Trace, TraceFrame{Name: fmt.Sprint("f", i)})
+	}
+
+	lines := strings.Split(strings.TrimSuffix(err.StackTrace(), "\n"), "\n")
+	tr.AssertEqual(len(lines), maxTraceFrames+1)
+	tr.AssertEqual(lines[0], "  at f0 (called by builtin)")
+	tr.AssertEqual(lines[maxTraceFrames], "  ... 5 more")
+}
+
+func TestGeneratorClose(t *testing.T) {
+	tr := tester.New(t, "")
+
+	finished := false
+	gen := NewGenerator(func(yield func(Object) bool) {
+		defer func() { finished = true }()
+		for i := 0; yield(&NumberObj{Value: float64(i)}); i++ {
+		}
+	})
+
+	tr.AssertEqual(gen.Next().(*NumberObj).Value, float64(0))
+	tr.AssertTrue(!finished)
+
+	CloseIterator(gen)
+	tr.AssertTrue(finished, "the sequence returns when the generator is closed")
+	tr.AssertTrue(gen.Done())
+	tr.AssertTrue(gen.Next() == nil)
+}
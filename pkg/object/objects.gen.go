@@ -30,9 +30,11 @@ type StringObj struct {
 func (n *StringObj) Type() ObjectType { return OBJ_STRING }
 
 type FnLiteralObj struct {
-	Parameters []*ast.IdentifierExpr
+	Name       string
+	Parameters []*ast.Parameter
 	Body       *ast.BlockStmt
 	Env        *Environment
+	Generator  bool
 }
 
 func (n *FnLiteralObj) Type() ObjectType { return OBJ_FUNCTION_LITERAL }
@@ -45,12 +47,14 @@ func (n *ReturnObj) Type() ObjectType { return OBJ_RETURN }
 
 type ListObj struct {
 	Values []Object
+	Frozen bool
 }
 
 func (n *ListObj) Type() ObjectType { return OBJ_LIST }
 
 type MapObj struct {
-	Pairs map[HashKey]KeyValuePair
+	Pairs  map[HashKey]KeyValuePair
+	Frozen bool
 }
 
 func (n *MapObj) Type() ObjectType { return OBJ_MAP }
@@ -64,8 +68,9 @@ type ModuleObj struct {
 func (n *ModuleObj) Type() ObjectType { return OBJ_MODULE }
 
 type BuiltinObj struct {
-	Fn   BuiltinFn
-	Name string
+	Fn        BuiltinFn
+	ContextFn ContextBuiltinFn
+	Name      string
 }
 
 func (n *BuiltinObj) Type() ObjectType { return OBJ_BUILTIN }
@@ -79,10 +84,21 @@ func (n *IteratorObj) Type() ObjectType { return OBJ_ITERATOR }
 type ErrorObj struct {
 	Error error
 	Token *token.Token
+	Value Object
+	Trace []TraceFrame
 }
 
 func (n *ErrorObj) Type() ObjectType { return OBJ_ERROR }
 
+type ErrorValueObj struct {
+	Kind    string
+	Message string
+	Value   Object
+	Token   *token.Token
+}
+
+func (n *ErrorValueObj) Type() ObjectType { return OBJ_ERROR_VALUE }
+
 // this is gives us a compile time check to see of all the interafaces has been properly implemented
 func _() {
 	_ = Object(&BooleanObj{})
@@ -97,4 +113,5 @@ func _() {
 	_ = Object(&BuiltinObj{})
 	_ = Object(&IteratorObj{})
 	_ = Object(&ErrorObj{})
+	_ = Object(&ErrorValueObj{})
 }
@@ -20,11 +20,13 @@ func _() {
 	_ = x[OBJ_ITERATOR-10]
 	_ = x[OBJ_MODULE-11]
 	_ = x[OBJ_ERROR-12]
+	_ = x[OBJ_ERROR_VALUE-13]
+	_ = x[OBJ_TAIL_CALL-14]
 }
 
-const _ObjectType_name = "OBJ_BOOLOBJ_NILOBJ_NUMBEROBJ_STRINGOBJ_FUNCTION_LITERALOBJ_RETURNOBJ_LISTOBJ_MAPOBJ_BUILTINOBJ_ITERATOROBJ_MODULEOBJ_ERROR"
+const _ObjectType_name = "OBJ_BOOLOBJ_NILOBJ_NUMBEROBJ_STRINGOBJ_FUNCTION_LITERALOBJ_RETURNOBJ_LISTOBJ_MAPOBJ_BUILTINOBJ_ITERATOROBJ_MODULEOBJ_ERROROBJ_ERROR_VALUEOBJ_TAIL_CALL"
 
-var _ObjectType_index = [...]uint8{0, 8, 15, 25, 35, 55, 65, 73, 80, 91, 103, 113, 122}
+var _ObjectType_index = [...]uint8{0, 8, 15, 25, 35, 55, 65, 73, 80, 91, 103, 113, 122, 137, 150}
 
 func (i ObjectType) String() string {
 	i -= 1
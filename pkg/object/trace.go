@@ -0,0 +1,39 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fredrikkvalvik/temp-lang/pkg/token"
+)
+
+// TraceFrame is a call that an error unwound through
+type TraceFrame struct {
+	Name string // the name of the called function
+	// the call site, nil when the function was called by a builtin
+	Token *token.Token
+}
+
+func (f TraceFrame) String() string {
+	if f.Token == nil {
+		return fmt.Sprintf("at %s (called by builtin)", f.Name)
+	}
+	return fmt.Sprintf("at %s (%s)", f.Name, f.Token.Pos.Location())
+}
+
+// the number of frames shown by StackTrace. A stack overflow unwinds
+// through thousands of calls, so the outermost are left out
+const maxTraceFrames = 20
+
+// StackTrace returns the frames of the error, innermost first, one per line
+func (b *ErrorObj) StackTrace() string {
+	var str strings.Builder
+	for i, frame := range b.Trace {
+		if i == maxTraceFrames {
+			fmt.Fprintf(&str, "  ... %d more\n", len(b.Trace)-maxTraceFrames)
+			break
+		}
+		fmt.Fprintf(&str, "  %s\n", frame)
+	}
+	return str.String()
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/fredrikkvalvik/temp-lang/pkg/ast"
+	"github.com/fredrikkvalvik/temp-lang/pkg/diagnostic"
 	"github.com/fredrikkvalvik/temp-lang/pkg/token"
 )
 
@@ -11,36 +13,102 @@ var (
 	ParseError = errors.New("Parse error")
 )
 
+// adds a diagnostic at tok, formatted like fmt.Errorf. The diagnostic is
+// returned so hints can be added to it.
+// a diagnostic at the same token as the last one is dropped, since it is
+// caused by the first, like the missing `)` after `(` that failed to parse
+func (p *Parser) errorAt(tok *token.Token, format string, args ...any) *diagnostic.Diagnostic {
+	d := diagnostic.Errorf(tok.Pos, format, args...)
+	if n := len(p.errors); n > 0 {
+		if last, ok := p.errors[n-1].(*diagnostic.Diagnostic); ok && last.Span == tok.Pos {
+			return d
+		}
+	}
+	p.errors = append(p.errors, d)
+	return d
+}
+
 func (p *Parser) expectPeekError(expect token.TokenType) {
-	err := p.expectError(&p.peekToken, expect)
-	p.errors = append(p.errors, err)
+	p.expectError(&p.peekToken, expect)
 }
 func (p *Parser) expectCurError(expect token.TokenType) {
-	err := p.expectError(&p.curToken, expect)
-	p.errors = append(p.errors, err)
+	p.expectError(&p.curToken, expect)
 }
 
-func (p *Parser) expectError(tok *token.Token, expect token.TokenType) error {
-	lcStr := lineColString(tok)
-
-	return fmt.Errorf("%s expected `%s`, got=`%s`", lcStr, expect, tok.Type)
+func (p *Parser) expectError(tok *token.Token, expect token.TokenType) {
+	d := p.errorAt(tok, "expected `%s`, got=`%s`", expect, tok.Type)
+	if expect == token.SEMICOLON {
+		d.WithHint("statements end at a newline or a `;`")
+	}
 }
 
 func (p *Parser) noParsletError(tok *token.Token) {
-	lcStr := lineColString(tok)
+	// the lexer has already reported the illegal token
+	if tok.Type == token.ILLEGAL {
+		return
+	}
+	p.errorAt(tok, "could not parser tok=%s", tok.Type)
+}
+
+// the number of errors reported before the parser stops. Errors after a
+// statement that could not be parsed are often caused by it
+const maxErrors = 10
 
-	err := fmt.Errorf("%s could not parser tok=%s", lcStr, tok.Type)
-	p.errors = append(p.errors, err)
+func (p *Parser) tooManyErrors() bool {
+	return len(p.errors) >= maxErrors
 }
 
-// will advance until curToken.Type == "SEMICOLON"
-func (p *Parser) recover() {
-	for !p.atEnd() || p.curToken.Type == token.SEMICOLON {
+// where a statement starts, and how deep it is nested in braces
+type statementStart struct {
+	offset int
+	depth  int
+}
+
+func (p *Parser) statementStart() statementStart {
+	return statementStart{offset: p.curToken.Pos.Start, depth: p.depth}
+}
+
+// skips the rest of a statement that could not be parsed, from where the
+// error left the parser. Stops at the `;` or newline that ends the statement,
+// or at a keyword that starts a statement or the `}` that closes the enclosing
+// block. Braces opened since start, also those opened by the failed
+// expression, are skipped up to their `}`.
+// returns true if curToken starts the next statement, and false if it starts
+// after curToken
+func (p *Parser) recover(start statementStart) bool {
+	for !p.atEnd() {
+		depth := p.depth - start.depth
+		if depth == 0 && p.curToken.Pos.Start != start.offset &&
+			(isStatementStart(p.curToken.Type) || p.curTokenIs(token.RBRACE)) {
+			return true
+		}
+		switch p.curToken.Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			depth--
+		case token.SEMICOLON:
+			if depth == 0 {
+				return false
+			}
+		}
+		if depth <= 0 && (isStatementStart(p.peekToken.Type) || p.peekTokenIs(token.RBRACE)) {
+			return false
+		}
 		p.advance()
 	}
+	return false
+}
+
+func isStatementStart(typ token.TokenType) bool {
+	switch typ {
+	case token.LET, token.CONST, token.FUNCTION, token.IMPORT, token.IF, token.EACH, token.WHILE,
+		token.RETURN, token.PRINT, token.TRY, token.THROW, token.DEFER, token.YIELD:
+		return true
+	}
+	return false
 }
 
-func lineColString(tok *token.Token) string {
-	line, col := tok.Pos.Position()
-	return fmt.Sprintf("[%d:%d]", line, col)
+func (p *Parser) parameterError(name *ast.IdentifierExpr, format string, args ...any) {
+	p.errorAt(&name.Token, "%w: %s", ParseError, fmt.Sprintf(format, args...))
 }
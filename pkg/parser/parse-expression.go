@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/fredrikkvalvik/temp-lang/pkg/ast"
@@ -52,6 +51,9 @@ func (p *Parser) parseBinary(left ast.Expr) ast.Expr {
 	//                ^
 
 	expr.Right = p.parseExpression(stickiness)
+	if expr.Right == nil {
+		return nil
+	}
 
 	return expr
 }
@@ -76,6 +78,9 @@ func (p *Parser) parseLogical(left ast.Expr) ast.Expr {
 	//                ^
 
 	expr.Right = p.parseExpression(stickiness)
+	if expr.Right == nil {
+		return nil
+	}
 
 	return expr
 }
@@ -112,11 +117,27 @@ func (p *Parser) parseParenPrefix() ast.Expr {
 		Token: p.curToken,
 	}
 
+	// `()`, `(a, b)` and `(a = 1)` can only be lambda parameters, so we try
+	// to parse a parameter list first and backtrack if it is not a lambda.
+	// `(a) => expr` is parsed as a paren expression and handled by parseLambda
+	state := p.save()
+	if params := p.parseFunctionArgs(); params != nil && p.peekTokenIs(token.ARROW) && !isSingleParameter(params) {
+		fun := &ast.FunctionLiteralExpr{Token: paren.Token, Arguments: params}
+		p.advance()
+		// ( a, b ) => expr
+		//          ^
+		return p.parseLambdaBody(fun)
+	}
+	p.restore(state)
+
 	p.advance()
 	// ( 1 + 2 ) * 3
 	//   ^
 
 	paren.Expression = p.parseExpression(LOWEST)
+	if paren.Expression == nil {
+		return nil
+	}
 
 	if !p.expectPeek(token.RPAREN) {
 		return nil
@@ -126,6 +147,53 @@ func (p *Parser) parseParenPrefix() ast.Expr {
 	return paren
 }
 
+// parses the `=>` of a lambda with a single parameter, `x => expr` or `(x) => expr`
+func (p *Parser) parseLambda(left ast.Expr) ast.Expr {
+	// x => expr
+	//   ^
+	fun := &ast.FunctionLiteralExpr{Token: p.curToken}
+
+	param := left
+	if paren, ok := left.(*ast.ParenExpr); ok {
+		param = paren.Expression
+	}
+	ident, ok := param.(*ast.IdentifierExpr)
+	if !ok {
+		p.errorAt(left.GetToken(), "%w: invalid lambda parameter `%s`", ParseError, left.String()).
+			WithHint("lambda parameters are names, like `x => x` or `(a, b) => a + b`")
+		return nil
+	}
+	fun.Arguments = []*ast.Parameter{{Name: ident}}
+
+	return p.parseLambdaBody(fun)
+}
+
+// parses the expression body of a lambda, and desugars it to a block
+// that returns the expression
+func (p *Parser) parseLambdaBody(fun *ast.FunctionLiteralExpr) ast.Expr {
+	// x => expr
+	//   ^
+	arrow := p.curToken
+	p.advance()
+	// x => expr
+	//      ^
+	body := p.parseExpression(LOWEST)
+	if body == nil {
+		return nil
+	}
+
+	fun.Body = &ast.BlockStmt{
+		Token:      arrow,
+		Statements: []ast.Stmt{&ast.ReturnStmt{Token: arrow, Value: body}},
+	}
+
+	return fun
+}
+
+func isSingleParameter(params []*ast.Parameter) bool {
+	return len(params) == 1 && params[0].Default == nil && !params[0].Rest
+}
+
 func (p *Parser) parseFunctionLiteral() ast.Expr {
 	// fn ( arg1, arg2 ) { ... }
 	// ^
@@ -137,6 +205,9 @@ func (p *Parser) parseFunctionLiteral() ast.Expr {
 	// fn ( arg1, arg2 ) { ... }
 	//    ^
 	fun.Arguments = p.parseFunctionArgs()
+	if fun.Arguments == nil {
+		return nil
+	}
 
 	// fn ( arg1, arg2 ) { ... }
 	//                 ^
@@ -147,63 +218,205 @@ func (p *Parser) parseFunctionLiteral() ast.Expr {
 	// fn ( arg1, arg2 ) { ... }
 	//                   ^
 
-	fun.Body = p.parseBlockStatement()
+	p.parseFunctionBody(fun)
 	// fn ( arg1, arg2 ) { ... }
 	//                         ^
 
 	return fun
 }
 
-func (p *Parser) parseFunctionArgs() []*ast.IdentifierExpr {
-	args := []*ast.IdentifierExpr{}
+// parses the body of fun, and keeps track of fun so a `yield` in the body
+// can mark it as a generator
+func (p *Parser) parseFunctionBody(fun *ast.FunctionLiteralExpr) {
+	p.functions = append(p.functions, fun)
+	fun.Body = p.parseBlockStatement()
+	p.functions = p.functions[:len(p.functions)-1]
+}
 
-	// fn ( arg1, arg2 ) { ... }
+func (p *Parser) parseFunctionArgs() []*ast.Parameter {
+	args := []*ast.Parameter{}
+
+	// fn ( arg1, arg2 = expr, ...rest ) { ... }
 	//    ^
 
 	// handle case with no args
 	if p.peekTokenIs(token.RPAREN) {
 		p.advance()
-		// fn ( arg1, arg2 ) { ... }
-		//                 ^
+		// fn ( ) { ... }
+		//      ^
 		return args
 	}
 
-	p.advance()
-	// fn ( arg1, arg2 ) { ... }
-	//      ^
-	firstArg := &ast.IdentifierExpr{Token: p.curToken, Value: p.curToken.Lexeme}
-	args = append(args, firstArg)
+	for {
+		p.advance()
+		// fn ( arg1, arg2 = expr, ...rest ) { ... }
+		//      ^
+		param := p.parseParameter()
+		if param == nil {
+			return nil
+		}
 
-	for p.peekTokenIs(token.COMMA) {
-		// fn ( arg1, arg2 ) { ... }
-		//         ^
+		if len(args) > 0 {
+			last := args[len(args)-1]
+			if last.Rest {
+				p.parameterError(param.Name, "rest parameter `%s` must be the last parameter", last.Name.Value)
+				return nil
+			}
+			if last.Default != nil && param.Default == nil && !param.Rest {
+				p.parameterError(param.Name, "parameter `%s` without a default value can't follow parameters with default values", param.Name.Value)
+				return nil
+			}
+		}
+		args = append(args, param)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.advance()
-		// fn ( arg1, arg2 ) { ... }
+		// fn ( arg1, arg2 = expr, ...rest ) { ... }
 		//          ^
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	// fn ( arg1, arg2 = expr, ...rest ) { ... }
+	//                                 ^
+
+	return args
+}
+
+// parses `name`, `name = default` or `...name`
+func (p *Parser) parseParameter() *ast.Parameter {
+	param := &ast.Parameter{}
+
+	if p.curTokenIs(token.ELLIPSIS) {
+		// ...rest
+		// ^
+		param.Rest = true
 		p.advance()
-		// fn ( arg1, arg2 ) { ... }
-		//            ^
-		arg := &ast.IdentifierExpr{Token: p.curToken, Value: p.curToken.Lexeme}
-		args = append(args, arg)
 	}
 
+	if !p.curTokenIs(token.IDENT) {
+		p.expectCurError(token.IDENT)
+		return nil
+	}
+	// arg = expr
+	// ^
+	param.Name = &ast.IdentifierExpr{Token: p.curToken, Value: p.curToken.Lexeme}
+
+	if !param.Rest && p.peekTokenIs(token.ASSIGN) {
+		p.advance()
+		p.advance()
+		// arg = expr
+		//       ^
+		param.Default = p.parseExpression(ASSIGN)
+		if param.Default == nil {
+			return nil
+		}
+	}
+
+	return param
+}
+
+// parses `left |> f(args)` and lowers it to the call `f(left, args)`.
+// A right side that is not a call, `left |> f`, becomes `f(left)`
+func (p *Parser) parsePipe(left ast.Expr) ast.Expr {
+	// xs |> f ( args )
+	//    ^
+	pipe := p.curToken
+
 	p.advance()
-	// fn ( arg1, arg2 ) { ... }
-	//                 ^
+	// xs |> f ( args )
+	//       ^
+	// the right side is the function being called, so operators after the
+	// call apply to its result: xs |> sum() + 1 is sum(xs) + 1
+	right := p.parseExpression(PREFIX)
+	if right == nil {
+		return nil
+	}
 
-	return args
+	call, ok := right.(*ast.CallExpr)
+	if !ok {
+		call = &ast.CallExpr{Token: pipe, Callee: right, Arguments: []ast.Expr{}}
+	}
+	call.Arguments = append([]ast.Expr{left}, call.Arguments...)
 
+	return call
 }
 
 func (p *Parser) parseCall(left ast.Expr) ast.Expr {
 	fun := &ast.CallExpr{Token: p.curToken}
 	fun.Callee = left
 
-	fun.Arguments = p.parseExpressionList(token.RPAREN)
+	fun.Arguments = p.parseCallArguments()
 
 	return fun
 }
 
+// parses the arguments of a call. An argument is either an expression, or a named argument `name: expr`
+func (p *Parser) parseCallArguments() []ast.Expr {
+	args := []ast.Expr{}
+
+	// fn ( expr1, name: expr2 )
+	//    ^
+	if p.peekTokenIs(token.RPAREN) {
+		p.advance()
+		// fn ( )
+		//      ^
+		return args
+	}
+
+	seenNamed := false
+	for {
+		p.advance()
+		// fn ( expr1, name: expr2 )
+		//      ^
+		var arg ast.Expr
+		if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
+			named := &ast.NamedArgumentExpr{
+				Token: p.curToken,
+				Name:  &ast.IdentifierExpr{Token: p.curToken, Value: p.curToken.Lexeme},
+			}
+			p.advance()
+			p.advance()
+			// fn ( expr1, name: expr2 )
+			//                   ^
+			named.Value = p.parseExpression(LOWEST)
+			if named.Value == nil {
+				return nil
+			}
+			arg = named
+			seenNamed = true
+		} else {
+			if seenNamed {
+				p.errorAt(&p.curToken, "%w: positional argument can't follow named arguments", ParseError)
+				return nil
+			}
+			arg = p.parseSpreadOrExpression()
+			if arg == nil {
+				return nil
+			}
+		}
+		args = append(args, arg)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.advance()
+		// fn ( expr1, name: expr2 )
+		//           ^
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	// fn ( expr1, name: expr2 )
+	//                         ^
+
+	return args
+}
+
 func (p *Parser) parseIdent() ast.Expr {
 	ident := &ast.IdentifierExpr{
 		Token:           p.curToken,
@@ -220,7 +433,7 @@ func (p *Parser) parseNumberLiteral() ast.Expr {
 	}
 	num, err := strconv.ParseFloat(p.curToken.Lexeme, 64)
 	if err != nil {
-		p.errors = append(p.errors, fmt.Errorf("could not parse string=%s to number", p.curToken.Lexeme))
+		p.errorAt(&p.curToken, "could not parse string=%s to number", p.curToken.Lexeme)
 		return nil
 	}
 
@@ -259,6 +472,9 @@ func (p *Parser) parseListLiteralExpression() ast.Expr {
 	}
 
 	listLiteral.Items = p.parseExpressionList(token.RBRACKET)
+	if listLiteral.Items == nil {
+		return nil
+	}
 
 	// [ item1, item2 ]
 	//                ^
@@ -281,7 +497,11 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expr {
 	// [ expr1, expr2 ]
 	//   ^
 
-	list = append(list, p.parseExpression(LOWEST))
+	item := p.parseSpreadOrExpression()
+	if item == nil {
+		return nil
+	}
+	list = append(list, item)
 	// [ expr1, expr2 ]
 	//       ^
 
@@ -292,7 +512,11 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expr {
 		p.advance()
 		// [ expr1, expr2 ]
 		//          ^
-		list = append(list, p.parseExpression(LOWEST))
+		item := p.parseSpreadOrExpression()
+		if item == nil {
+			return nil
+		}
+		list = append(list, item)
 	}
 
 	// [ expr1, expr2 ]
@@ -311,8 +535,9 @@ func (p *Parser) parseGetExpression(left ast.Expr) ast.Expr {
 	// obj . ident
 	//     ^
 	get := &ast.GetExpr{
-		Token: p.curToken,
-		Obj:   left,
+		Token:    p.curToken,
+		Obj:      left,
+		Optional: p.curTokenIs(token.OPTIONAL_DOT),
 	}
 	if !p.expectPeek(token.IDENT) {
 		return nil
@@ -330,18 +555,59 @@ func (p *Parser) parseGetExpression(left ast.Expr) ast.Expr {
 func (p *Parser) parseIndexExpression(left ast.Expr) ast.Expr {
 	// list [ expr ]
 	//      ^
-	expr := &ast.IndexExpr{Token: p.curToken, Left: left}
+	expr := &ast.IndexExpr{Token: p.curToken, Left: left, Optional: p.curTokenIs(token.OPTIONAL_LBRACKET)}
 
 	p.advance()
 	// list [ expr ]
 	//        ^
-	expr.Index = p.parseExpression(LOWEST)
+	if !p.curTokenIs(token.COLON) {
+		expr.Index = p.parseExpression(LOWEST)
+		if expr.Index == nil {
+			return nil
+		}
+
+		if !p.peekTokenIs(token.COLON) {
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+			// list [ expr ]
+			//             ^
+			return expr
+		}
+		p.advance()
+	}
+	// list [ start : end : step ]
+	//              ^
+	expr.Slice = true
+
+	if !p.peekTokenIs(token.COLON) && !p.peekTokenIs(token.RBRACKET) {
+		p.advance()
+		// list [ start : end : step ]
+		//                ^
+		expr.End = p.parseExpression(LOWEST)
+		if expr.End == nil {
+			return nil
+		}
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.advance()
+		// list [ start : end : step ]
+		//                    ^
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.advance()
+			expr.Step = p.parseExpression(LOWEST)
+			if expr.Step == nil {
+				return nil
+			}
+		}
+	}
 
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
-	// list [ expr ]
-	//             ^
+	// list [ start : end : step ]
+	//                           ^
 
 	return expr
 }
@@ -352,16 +618,19 @@ func (p *Parser) parseMapLiteralExpression() ast.Expr {
 	mapLit := &ast.MapLiteralExpr{Token: p.curToken}
 
 	mapLit.KeyValues = p.parseExpressionPairs(token.RBRACE)
+	if mapLit.KeyValues == nil {
+		return nil
+	}
 	// { key1: value1,  key2: value2, }
 	//                               ^
 
 	return mapLit
 }
 
-func (p *Parser) parseExpressionPairs(end token.TokenType) map[ast.Expr]ast.Expr {
+func (p *Parser) parseExpressionPairs(end token.TokenType) []*ast.KeyValue {
 	// { key1 : value1,  key2 : value2, }
 	// ^
-	pairs := map[ast.Expr]ast.Expr{}
+	pairs := []*ast.KeyValue{}
 
 	// handle empty case
 	for p.peekTokenIs(end) {
@@ -373,24 +642,11 @@ func (p *Parser) parseExpressionPairs(end token.TokenType) map[ast.Expr]ast.Expr
 	p.advance()
 	// { key1 : value1, key2 : value2, }
 	//   ^
-	key := p.parseExpression(LOWEST)
-
-	// { key1 : value1, key2 : value2, }
-	//      ^
-	if !p.expectPeek(token.COLON) {
+	pair := p.parseKeyValue()
+	if pair == nil {
 		return nil
 	}
-	// { key1 : value1, key2 : value2, }
-	//        ^
-
-	p.advance()
-	// { key1 : value1, key2 : value2, }
-	//          ^
-
-	value := p.parseExpression(LOWEST)
-	// { key1 : value1, key2 : value2, }
-	//               ^
-	pairs[key] = value
+	pairs = append(pairs, pair)
 
 	for p.peekTokenIs(token.COMMA) {
 		p.advance()
@@ -407,21 +663,11 @@ func (p *Parser) parseExpressionPairs(end token.TokenType) map[ast.Expr]ast.Expr
 		p.advance()
 		// { key1 : value1, key2 : value2, }
 		//                  ^
-		key := p.parseExpression(LOWEST)
-		if !p.expectPeek(token.COLON) {
+		pair := p.parseKeyValue()
+		if pair == nil {
 			return nil
 		}
-		// { key1 : value1, key2 : value2, }
-		//                       ^
-
-		p.advance()
-		// { key1 : value1, key2 : value2, }
-		//                         ^
-
-		value := p.parseExpression(LOWEST)
-		// { key1 : value1, key2 : value2, }
-		//                              ^
-		pairs[key] = value
+		pairs = append(pairs, pair)
 	}
 
 	// handle possible automatic semicolon insertion
@@ -436,3 +682,309 @@ func (p *Parser) parseExpressionPairs(end token.TokenType) map[ast.Expr]ast.Expr
 
 	return pairs
 }
+
+// parses a single `key : value` pair, or a spread entry `...expr`
+func (p *Parser) parseKeyValue() *ast.KeyValue {
+	if p.curTokenIs(token.ELLIPSIS) {
+		// { ...expr, key : value }
+		//   ^
+		spread := p.parseSpreadOrExpression()
+		if spread == nil {
+			return nil
+		}
+		return &ast.KeyValue{Value: spread}
+	}
+
+	// { key1 : value1 }
+	//   ^
+	key := p.parseExpression(LOWEST)
+
+	// { key1 : value1 }
+	//      ^
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	// { key1 : value1 }
+	//        ^
+
+	p.advance()
+	// { key1 : value1 }
+	//          ^
+
+	value := p.parseExpression(LOWEST)
+	// { key1 : value1 }
+	//               ^
+
+	return &ast.KeyValue{Key: key, Value: value}
+}
+
+// parses an expression, or a spread `...expr` where the expression is expanded into
+// the surrounding list, map or call arguments
+func (p *Parser) parseSpreadOrExpression() ast.Expr {
+	if !p.curTokenIs(token.ELLIPSIS) {
+		return p.parseExpression(LOWEST)
+	}
+
+	// ... expr
+	// ^
+	spread := &ast.SpreadExpr{Token: p.curToken}
+	p.advance()
+	// ... expr
+	//     ^
+	spread.Value = p.parseExpression(LOWEST)
+	if spread.Value == nil {
+		return nil
+	}
+
+	return spread
+}
+
+func (p *Parser) parseIfExpression() ast.Expr {
+	// if expr { ... } else { ... }
+	// ^
+	ifExpr := &ast.IfExpr{Token: p.curToken}
+
+	p.advance()
+	// if expr { ... } else { ... }
+	//    ^
+	ifExpr.Condition = p.parseExpression(LOWEST)
+	if ifExpr.Condition == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	// if expr { ... } else { ... }
+	//         ^
+	ifExpr.Then = p.parseBlockStatement()
+	if !p.curTokenIs(token.RBRACE) {
+		p.expectCurError(token.RBRACE)
+		return nil
+	}
+
+	if !p.peekTokenIs(token.ELSE) {
+		return ifExpr
+	}
+	p.advance()
+	// if expr { ... } else { ... }
+	//                 ^
+	p.advance()
+
+	switch p.curToken.Type {
+	case token.IF:
+		// if expr { ... } else if expr { ... }
+		//                      ^
+		tok := p.curToken
+		elseIf := p.parseIfExpression()
+		if elseIf == nil {
+			return nil
+		}
+		ifExpr.Else = &ast.ExpressionStmt{Token: tok, Expression: elseIf}
+
+	case token.LBRACE:
+		// if expr { ... } else { ... }
+		//                      ^
+		ifExpr.Else = p.parseBlockStatement()
+		if !p.curTokenIs(token.RBRACE) {
+			p.expectCurError(token.RBRACE)
+			return nil
+		}
+
+	default:
+		p.expectCurError(token.LBRACE)
+		return nil
+	}
+	// if expr { ... } else { ... }
+	//                            ^
+
+	return ifExpr
+}
+
+func (p *Parser) parseRange(start ast.Expr) ast.Expr {
+	// start .. end step expr
+	//       ^
+	rangeExpr := &ast.RangeExpr{
+		Token:     p.curToken,
+		Start:     start,
+		Inclusive: p.curTokenIs(token.RANGE_INCL),
+	}
+	stickiness := p.curStickiness()
+
+	p.advance()
+	// start .. end step expr
+	//          ^
+	rangeExpr.End = p.parseExpression(stickiness)
+	if rangeExpr.End == nil {
+		return nil
+	}
+
+	// `step` is not a keyword, so it can still be used as a variable name
+	if p.peekTokenIs(token.IDENT) && p.peekToken.Lexeme == "step" {
+		p.advance()
+		p.advance()
+		// start .. end step expr
+		//                   ^
+		rangeExpr.Step = p.parseExpression(stickiness)
+		if rangeExpr.Step == nil {
+			return nil
+		}
+	}
+
+	return rangeExpr
+}
+
+// `?` is either the postfix `value?`, or the start of a ternary `cond ? a : b`
+func (p *Parser) parseQuestion(left ast.Expr) ast.Expr {
+	if p.curIsPropagate() {
+		return p.parsePropagate(left)
+	}
+	return p.parseTernary(left)
+}
+
+// reports if the `?` at curToken is the postfix `value?`. It is a ternary
+// when it is followed by an expression and a `:`
+func (p *Parser) curIsPropagate() bool {
+	offset := p.curToken.Pos.Start
+	if propagate, ok := p.propagates[offset]; ok {
+		return propagate
+	}
+
+	state := p.save()
+	p.advance()
+	// cond ? then : else
+	//        ^
+	ternary := p.prefixParselets[p.curToken.Type] != nil &&
+		p.parseExpression(LOWEST) != nil &&
+		p.peekTokenIs(token.COLON)
+	p.restore(state)
+
+	p.propagates[offset] = !ternary
+	return !ternary
+}
+
+func (p *Parser) parseTernary(condition ast.Expr) ast.Expr {
+	// cond ? then : else
+	//      ^
+	ternary := &ast.TernaryExpr{Token: p.curToken, Condition: condition}
+	stickiness := p.curStickiness()
+
+	p.advance()
+	// cond ? then : else
+	//        ^
+	ternary.Then = p.parseExpression(LOWEST)
+	if ternary.Then == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	p.advance()
+	// cond ? then : else
+	//               ^
+	// parse with a lower stickiness so nested ternaries group to the right
+	ternary.Else = p.parseExpression(stickiness - 1)
+	if ternary.Else == nil {
+		return nil
+	}
+
+	return ternary
+}
+
+func (p *Parser) parsePropagate(value ast.Expr) ast.Expr {
+	// value ?
+	//       ^
+	return &ast.PropagateExpr{Token: p.curToken, Value: value}
+}
+
+func (p *Parser) parseMatchExpression() ast.Expr {
+	// match expr { pattern => body, ... }
+	// ^
+	match := &ast.MatchExpr{Token: p.curToken, Arms: []*ast.MatchArm{}}
+
+	p.advance()
+	// match expr { pattern => body, ... }
+	//       ^
+	match.Subject = p.parseExpression(LOWEST)
+	if match.Subject == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	// match expr { pattern => body, ... }
+	//            ^
+
+	for {
+		p.advance()
+		// arms can be separated by ',' or newlines
+		for p.curTokenIs(token.COMMA) || p.curTokenIs(token.SEMICOLON) {
+			p.advance()
+		}
+		if p.curTokenIs(token.RBRACE) || p.atEnd() {
+			break
+		}
+		// match expr { pattern => body, ... }
+		//              ^
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		match.Arms = append(match.Arms, arm)
+	}
+
+	if !p.curTokenIs(token.RBRACE) {
+		p.expectCurError(token.RBRACE)
+		return nil
+	}
+	// match expr { pattern => body, ... }
+	//                                   ^
+
+	return match
+}
+
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	// pattern if guard => body
+	// ^
+	arm := &ast.MatchArm{}
+
+	arm.Pattern = p.parsePattern()
+	if arm.Pattern == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.advance()
+		p.advance()
+		// pattern if guard => body
+		//            ^
+		// the guard is parsed above lambda stickiness so `=>` ends the guard
+		arm.Guard = p.parseExpression(LAMBDA)
+		if arm.Guard == nil {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+	p.advance()
+	// pattern if guard => body
+	//                     ^
+
+	if p.curTokenIs(token.LBRACE) {
+		arm.Body = p.parseBlockStatement()
+		// pattern => { ... }
+		//                  ^
+	} else {
+		body := &ast.ExpressionStmt{Token: p.curToken, Expression: p.parseExpression(LOWEST)}
+		if body.Expression == nil {
+			return nil
+		}
+		arm.Body = body
+	}
+
+	return arm
+}
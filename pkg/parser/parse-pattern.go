@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"github.com/fredrikkvalvik/temp-lang/pkg/ast"
+	"github.com/fredrikkvalvik/temp-lang/pkg/token"
+)
+
+// parses a pattern starting at curToken. Returns nil if the pattern is invalid
+func (p *Parser) parsePattern() ast.Pattern {
+	switch p.curToken.Type {
+	case token.IDENT:
+		if p.curToken.Lexeme == "_" {
+			return &ast.WildcardPattern{Token: p.curToken}
+		}
+		return &ast.BindingPattern{
+			Token: p.curToken,
+			Name:  &ast.IdentifierExpr{Token: p.curToken, Value: p.curToken.Lexeme},
+		}
+
+	case token.NUMBER, token.STRING, token.TRUE, token.FALSE:
+		return &ast.LiteralPattern{
+			Token: p.curToken,
+			Value: p.prefixParselets[p.curToken.Type](),
+		}
+
+	case token.MINUS:
+		// -1
+		// ^
+		if !p.peekTokenIs(token.NUMBER) {
+			p.expectPeekError(token.NUMBER)
+			return nil
+		}
+		return &ast.LiteralPattern{
+			Token: p.curToken,
+			Value: p.parsePrefix(),
+		}
+
+	case token.LBRACKET:
+		return p.parseListPattern()
+
+	case token.LBRACE:
+		return p.parseMapPattern()
+	}
+
+	p.noParsletError(&p.curToken)
+	return nil
+}
+
+func (p *Parser) parseListPattern() ast.Pattern {
+	// [ pattern1, pattern2 ]
+	// ^
+	list := &ast.ListPattern{Token: p.curToken, Elements: []ast.Pattern{}}
+
+	if p.peekTokenIs(token.RBRACKET) {
+		p.advance()
+		// [ ]
+		//   ^
+		return list
+	}
+
+	for {
+		p.advance()
+		// [ pattern1, pattern2 ]
+		//   ^
+		if p.curTokenIs(token.ELLIPSIS) {
+			// [ pattern1, ...rest ]
+			//             ^
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			list.Rest = p.parsePattern()
+			// the rest element has to be the last element in the list
+			break
+		}
+
+		elem := p.parsePattern()
+		if elem == nil {
+			return nil
+		}
+		list.Elements = append(list.Elements, elem)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.advance()
+		// [ pattern1, pattern2 ]
+		//           ^
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	// [ pattern1, pattern2 ]
+	//                      ^
+
+	return list
+}
+
+func (p *Parser) parseMapPattern() ast.Pattern {
+	// { "key": pattern, name }
+	// ^
+	mapPattern := &ast.MapPattern{Token: p.curToken}
+
+	for {
+		p.advance()
+		// handle possible automatic semicolon insertion
+		for p.curTokenIs(token.SEMICOLON) {
+			p.advance()
+		}
+		// { "key": pattern, name }
+		//   ^
+		if p.curTokenIs(token.RBRACE) {
+			break
+		}
+
+		var key ast.Expr
+		switch p.curToken.Type {
+		case token.IDENT:
+			// identifiers are used as string keys, so `{name}` matches the key "name"
+			key = &ast.StringLiteralExpr{Token: p.curToken, Value: p.curToken.Lexeme}
+		case token.STRING, token.NUMBER, token.TRUE, token.FALSE:
+			key = p.prefixParselets[p.curToken.Type]()
+		default:
+			p.noParsletError(&p.curToken)
+			return nil
+		}
+
+		var value ast.Pattern
+		if p.peekTokenIs(token.COLON) {
+			p.advance()
+			p.advance()
+			// { "key": pattern, name }
+			//          ^
+			value = p.parsePattern()
+			if value == nil {
+				return nil
+			}
+		} else if p.curTokenIs(token.IDENT) {
+			// { name }
+			//   ^ shorthand for { name: name }
+			value = &ast.BindingPattern{
+				Token: p.curToken,
+				Name:  &ast.IdentifierExpr{Token: p.curToken, Value: p.curToken.Lexeme},
+			}
+		} else {
+			p.expectPeekError(token.COLON)
+			return nil
+		}
+
+		mapPattern.Keys = append(mapPattern.Keys, key)
+		mapPattern.Values = append(mapPattern.Values, value)
+
+		p.consume(token.SEMICOLON)
+		if !p.peekTokenIs(token.COMMA) {
+			if !p.expectPeek(token.RBRACE) {
+				return nil
+			}
+			break
+		}
+		p.advance()
+		// { "key": pattern, name }
+		//                 ^
+	}
+	// { "key": pattern, name }
+	//                        ^
+
+	return mapPattern
+}
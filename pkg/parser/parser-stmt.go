@@ -1,8 +1,6 @@
 package parser
 
 import (
-	"fmt"
-
 	"github.com/fredrikkvalvik/temp-lang/pkg/ast"
 	"github.com/fredrikkvalvik/temp-lang/pkg/token"
 )
@@ -10,55 +8,81 @@ import (
 func (p *Parser) parseStatement() ast.Stmt {
 	var node ast.Stmt
 	switch p.curToken.Type {
-	case token.LET:
-		node = p.parseLetStatment()
+	case token.LET, token.CONST:
+		node = stmtOrNil(p.parseLetStatment())
 	case token.FUNCTION:
-		node = p.parseFunctionStatment()
+		node = stmtOrNil(p.parseFunctionStatment())
 	case token.IMPORT:
-		node = p.parseImportStatement()
+		node = stmtOrNil(p.parseImportStatement())
 	case token.IF:
-		node = p.parseIfStatement()
+		node = stmtOrNil(p.parseIfStatement())
 	case token.LBRACE:
-		node = p.parseBlockStatement()
+		node = stmtOrNil(p.parseBlockStatement())
 	case token.RETURN:
-		node = p.parseReturnStatement()
+		node = stmtOrNil(p.parseReturnStatement())
 	case token.EACH:
-		node = p.parseIteratorStatement()
+		node = stmtOrNil(p.parseIteratorStatement())
 	case token.WHILE:
-		node = p.parseWhileStatement()
+		node = stmtOrNil(p.parseWhileStatement())
 	case token.PRINT:
-		node = p.parsePrintStatement()
+		node = stmtOrNil(p.parsePrintStatement())
+	case token.TRY:
+		node = stmtOrNil(p.parseTryStatement())
+	case token.THROW:
+		node = stmtOrNil(p.parseThrowStatement())
+	case token.DEFER:
+		node = stmtOrNil(p.parseDeferStatement())
+	case token.YIELD:
+		node = stmtOrNil(p.parseYieldStatement())
 
 	default:
-		node = p.parseExpressionStatement()
+		node = stmtOrNil(p.parseExpressionStatement())
 	}
 
-	if node != nil {
-		return node
-	} else {
+	return node
+}
+
+// the parse functions return nil pointers of their own type when they fail, which
+// are not equal to nil once they are an ast.Stmt. stmtOrNil turns them into a plain nil
+func stmtOrNil[S any, T interface {
+	*S
+	ast.Stmt
+}](stmt T) ast.Stmt {
+	if stmt == nil {
 		return nil
 	}
-
+	return stmt
 }
 
 func (p *Parser) parseLetStatment() *ast.LetStmt {
 	// let   ident    =    "hei"
 	// ^
-	letStmt := &ast.LetStmt{Token: p.curToken}
+	letStmt := &ast.LetStmt{Token: p.curToken, Const: p.curTokenIs(token.CONST)}
 
-	if !p.expectPeek(token.IDENT) {
-		return nil
-	}
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.advance()
+		// let   [a, b]    =    list
+		//       ^
+		letStmt.Pattern = p.parsePattern()
+		if letStmt.Pattern == nil {
+			return nil
+		}
+		// let   [a, b]    =    list
+		//            ^
+	} else {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
 
-	// let   ident    =    "hei"
-	//       ^
-	letStmt.Name = &ast.IdentifierExpr{
-		Token: p.curToken,
-		Value: p.curToken.Lexeme,
+		// let   ident    =    "hei"
+		//       ^
+		letStmt.Name = &ast.IdentifierExpr{
+			Token: p.curToken,
+			Value: p.curToken.Lexeme,
+		}
 	}
 
 	if !p.expectPeek(token.ASSIGN) {
-		p.recover()
 		return nil
 	}
 	// let   ident    =    "hei"
@@ -70,6 +94,14 @@ func (p *Parser) parseLetStatment() *ast.LetStmt {
 	// let   ident    =    "hei"
 	//                     ^
 	letStmt.Value = p.parseExpression(LOWEST)
+	if letStmt.Value == nil {
+		return nil
+	}
+
+	// name anonymous functions after the variable they are assigned to
+	if fun, ok := letStmt.Value.(*ast.FunctionLiteralExpr); ok && fun.Name == "" && letStmt.Name != nil {
+		fun.Name = letStmt.Name.Value
+	}
 
 	// consume ';' if present. Some expressions dont naturally end with ';'
 	p.consume(token.SEMICOLON)
@@ -131,8 +163,11 @@ func (p *Parser) parseFunctionStatment() *ast.LetStmt {
 	}
 	// fn name ( arg1, arg2 ) { ... }
 	//         ^
-	fun := &ast.FunctionLiteralExpr{Token: p.curToken}
+	fun := &ast.FunctionLiteralExpr{Token: p.curToken, Name: let.Name.Value}
 	fun.Arguments = p.parseFunctionArgs()
+	if fun.Arguments == nil {
+		return nil
+	}
 	// fn ( arg1, arg2 ) { ... }
 	//                 ^
 
@@ -142,7 +177,7 @@ func (p *Parser) parseFunctionStatment() *ast.LetStmt {
 	// fn ( arg1, arg2 ) { ... }
 	//                   ^
 
-	fun.Body = p.parseBlockStatement()
+	p.parseFunctionBody(fun)
 	// fn ( arg1, arg2 ) { ... }
 	//                         ^
 
@@ -162,6 +197,9 @@ func (p *Parser) parsePrintStatement() *ast.PrintStmt {
 	// print expr1, expr2 ;
 	//     ^
 	list := p.parseExpressionList(token.SEMICOLON)
+	if list == nil {
+		return nil
+	}
 
 	// print expr1, expr2 ;
 	//                    ^
@@ -184,11 +222,15 @@ func (p *Parser) parseBlockStatement() *ast.BlockStmt {
 	// { ... }
 	//   ^
 
-	for !p.curTokenIs(token.RBRACE) && !p.atEnd() {
+	for !p.curTokenIs(token.RBRACE) && !p.atEnd() && !p.tooManyErrors() {
+		start := p.statementStart()
 		stmt := p.parseStatement()
-
-		block.Statements = append(block.Statements, stmt)
-
+		if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		} else if p.recover(start) {
+			// curToken starts the next statement
+			continue
+		}
 		p.advance()
 	}
 
@@ -222,7 +264,6 @@ func (p *Parser) parseIfStatement() *ast.IfStmt {
 	// if expr { ... } else { ... }
 	//               ^
 	if !p.curTokenIs(token.RBRACE) {
-		p.recover()
 		return nil
 	}
 
@@ -231,7 +272,9 @@ func (p *Parser) parseIfStatement() *ast.IfStmt {
 		// if expr { ... } else { ... }
 		//                 ^
 
-		p.advance()
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
 		// if expr { ... } else { ... }
 		//                      ^
 		ifstmt.Else = ast.Stmt(p.parseBlockStatement())
@@ -239,7 +282,6 @@ func (p *Parser) parseIfStatement() *ast.IfStmt {
 		// if expr { ... } else { ... }
 		//                            ^
 		if !p.curTokenIs(token.RBRACE) {
-			p.recover()
 			return nil
 		}
 	}
@@ -282,25 +324,14 @@ func (p *Parser) parseIteratorStatement() *ast.IterStmt {
 
 	p.advance()
 
-	if p.curTokenIs(token.LBRACE) {
-		p.errors = append(p.errors, fmt.Errorf("%w: expected expression after each", ParseError))
-		return nil
-	}
-
 	// each items { ... }
 	// each item : items { ... }
 	//      ^
-	first := p.parseExpression(LOWEST)
-	if first == nil {
-		return nil
-	}
-	// each items { ... }
-	// each  item : items { ... }
-	//          ^
-
-	if !p.peekTokenIs(token.LBRACE) {
-		// set first to iterable and return each with no local var name
-		each.Name = first
+	// a pattern and an iterable can start with the same tokens, so we try
+	// to parse a pattern first and backtrack if it is not followed by ':'
+	state := p.save()
+	if name := p.parsePattern(); name != nil && p.peekTokenIs(token.COLON) {
+		each.Name = name
 		p.advance()
 		p.advance()
 		// each item : items { ... }
@@ -314,12 +345,28 @@ func (p *Parser) parseIteratorStatement() *ast.IterStmt {
 		//                 ^
 		each.Iterable = iterable
 
-		p.advance()
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
 		// each item : items { ... }
 		//                   ^
 	} else {
-		each.Iterable = first
-		p.advance()
+		p.restore(state)
+
+		if p.curTokenIs(token.LBRACE) {
+			p.errorAt(&p.curToken, "%w: expected expression after each", ParseError)
+			return nil
+		}
+
+		iterable := p.parseExpression(LOWEST)
+		if iterable == nil {
+			return nil
+		}
+		each.Iterable = iterable
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
 		// each items { ... }
 		//            ^
 	}
@@ -357,6 +404,141 @@ func (p *Parser) parseWhileStatement() *ast.WhileStmt {
 	return while
 }
 
+func (p *Parser) parseTryStatement() *ast.TryStmt {
+	// try { ... } catch (e) { ... } finally { ... }
+	// ^
+	try := &ast.TryStmt{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	// try { ... } catch (e) { ... } finally { ... }
+	//     ^
+	try.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.advance()
+		// try { ... } catch (e) { ... } finally { ... }
+		//             ^
+		if p.peekTokenIs(token.LPAREN) {
+			p.advance()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			// try { ... } catch (e) { ... } finally { ... }
+			//                    ^
+			try.Param = &ast.IdentifierExpr{
+				Token: p.curToken,
+				Value: p.curToken.Lexeme,
+			}
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		// try { ... } catch (e) { ... } finally { ... }
+		//                       ^
+		try.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.advance()
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		// try { ... } catch (e) { ... } finally { ... }
+		//                                       ^
+		try.Finally = p.parseBlockStatement()
+	}
+
+	if try.Catch == nil && try.Finally == nil {
+		p.errorAt(&try.Token, "%w: expected catch or finally after try block", ParseError)
+		return nil
+	}
+
+	return try
+}
+
+func (p *Parser) parseThrowStatement() *ast.ThrowStmt {
+	// throw expr ;
+	// ^
+	throw := &ast.ThrowStmt{Token: p.curToken}
+
+	p.advance()
+	// throw expr ;
+	//       ^
+	if p.curTokenIs(token.SEMICOLON) || p.curTokenIs(token.RBRACE) {
+		p.errorAt(&throw.Token, "%w: expected expression after throw", ParseError)
+		return nil
+	}
+
+	throw.Value = p.parseExpression(LOWEST)
+	if throw.Value == nil {
+		return nil
+	}
+
+	p.consume(token.SEMICOLON)
+
+	return throw
+}
+
+func (p *Parser) parseDeferStatement() *ast.DeferStmt {
+	// defer expr ;
+	// ^
+	deferStmt := &ast.DeferStmt{Token: p.curToken}
+
+	p.advance()
+	// defer expr ;
+	//       ^
+	if p.curTokenIs(token.SEMICOLON) || p.curTokenIs(token.RBRACE) {
+		p.errorAt(&deferStmt.Token, "%w: expected expression after defer", ParseError)
+		return nil
+	}
+
+	deferStmt.Value = p.parseExpression(LOWEST)
+	if deferStmt.Value == nil {
+		return nil
+	}
+
+	p.consume(token.SEMICOLON)
+
+	return deferStmt
+}
+
+func (p *Parser) parseYieldStatement() *ast.YieldStmt {
+	// yield expr ;
+	// ^
+	yield := &ast.YieldStmt{Token: p.curToken}
+
+	// the resolver reports a yield outside of a function
+	if len(p.functions) > 0 {
+		p.functions[len(p.functions)-1].Generator = true
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE) {
+		// yield ;
+		// ^
+		p.consume(token.SEMICOLON)
+		return yield
+	}
+	p.advance()
+	// yield expr ;
+	//       ^
+	yield.Value = p.parseExpression(LOWEST)
+	if yield.Value == nil {
+		return nil
+	}
+
+	p.consume(token.SEMICOLON)
+
+	return yield
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStmt {
 	exprStmt := &ast.ExpressionStmt{
 		Token:      p.curToken,
@@ -382,6 +564,9 @@ func (p *Parser) parseExpression(stickiness int) ast.Expr {
 	}
 
 	left := prefix()
+	if left == nil {
+		return nil
+	}
 
 	for !p.peekTokenIs(token.SEMICOLON) && stickiness < p.peekStickiness() {
 		//   2      +     2
@@ -401,6 +586,9 @@ func (p *Parser) parseExpression(stickiness int) ast.Expr {
 		//          ^
 		// parse op as infix
 		left = infix(left)
+		if left == nil {
+			return nil
+		}
 	}
 
 	return left
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"slices"
+
 	"github.com/fredrikkvalvik/temp-lang/pkg/ast"
 	"github.com/fredrikkvalvik/temp-lang/pkg/lexer"
 	"github.com/fredrikkvalvik/temp-lang/pkg/token"
@@ -10,10 +12,15 @@ const (
 	_ int = iota
 	LOWEST
 	ASSIGN      // =
+	LAMBDA      // x => expr
+	TERNARY     // c ? a : b
+	NULLISH     // a ?? b
 	OR          // or
 	AND         // and
 	EQUALS      // ==
 	LESSGREATER // > or <
+	PIPE        // x |> f()
+	RANGE       // 0..10
 	SUM         //+ -
 	PRODUCT     //* /
 	PREFIX      //-X or !X
@@ -25,20 +32,30 @@ const (
 
 var stickinessMap = map[token.TokenType]int{
 	token.ASSIGN:   ASSIGN,
+	token.ARROW:    LAMBDA,
+	token.QUESTION: TERNARY,
+	token.NULLISH:  NULLISH,
 	token.OR:       OR,
 	token.AND:      AND,
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
 	token.LT:       LESSGREATER,
 	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.FUNCTION: CALL,
-	token.DOT:      GET,
-	token.LPAREN:   GROUPING,
-	token.LBRACKET: INDEX,
+	token.PIPE:     PIPE,
+
+	token.RANGE:      RANGE,
+	token.RANGE_INCL: RANGE,
+	token.PLUS:       SUM,
+	token.MINUS:      SUM,
+	token.SLASH:      PRODUCT,
+	token.ASTERISK:   PRODUCT,
+	token.FUNCTION:   CALL,
+	token.DOT:        GET,
+	token.LPAREN:     GROUPING,
+	token.LBRACKET:   INDEX,
+
+	token.OPTIONAL_DOT:      GET,
+	token.OPTIONAL_LBRACKET: INDEX,
 }
 
 type Parser struct {
@@ -46,11 +63,19 @@ type Parser struct {
 
 	curToken  token.Token
 	peekToken token.Token
+	// the number of `{` before curToken that are not closed
+	depth int
 
 	infixParselets  map[token.TokenType]infixFn
 	prefixParselets map[token.TokenType]prefixFn
 
 	errors []error
+
+	// the function literals that are being parsed, innermost last
+	functions []*ast.FunctionLiteralExpr
+
+	// if the `?` at a byte offset is the postfix `value?`, and not a ternary
+	propagates map[int]bool
 }
 
 type MyString = *string
@@ -63,6 +88,8 @@ func New(l *lexer.Lexer) *Parser {
 
 		infixParselets:  map[token.TokenType]infixFn{},
 		prefixParselets: map[token.TokenType]prefixFn{},
+
+		propagates: map[int]bool{},
 	}
 
 	// prepare curToken and peekToken
@@ -78,10 +105,13 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseListLiteralExpression)
 	p.registerPrefix(token.LBRACE, p.parseMapLiteralExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
+	p.registerPrefix(token.IF, p.parseIfExpression)
 
 	// complex literals
 	p.registerInfix(token.LPAREN, p.parseCall)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.OPTIONAL_LBRACKET, p.parseIndexExpression)
 
 	// prefix
 	p.registerPrefix(token.BANG, p.parsePrefix)
@@ -100,23 +130,39 @@ func New(l *lexer.Lexer) *Parser {
 
 	// get
 	p.registerInfix(token.DOT, p.parseGetExpression)
+	p.registerInfix(token.OPTIONAL_DOT, p.parseGetExpression)
 
 	// logical
 	p.registerInfix(token.AND, p.parseLogical)
 	p.registerInfix(token.OR, p.parseLogical)
+	p.registerInfix(token.NULLISH, p.parseLogical)
 
 	// assign
 	p.registerInfix(token.ASSIGN, p.parseAssign)
 
+	// lambda
+	p.registerInfix(token.ARROW, p.parseLambda)
+
+	// pipe
+	p.registerInfix(token.PIPE, p.parsePipe)
+
+	// ternary, and the postfix error propagation `value?`
+	p.registerInfix(token.QUESTION, p.parseQuestion)
+
+	// range
+	p.registerInfix(token.RANGE, p.parseRange)
+	p.registerInfix(token.RANGE_INCL, p.parseRange)
+
 	return p
 }
 
 func (p *Parser) DidError() bool {
-	return len(p.errors) > 0
+	return len(p.errors) > 0 || p.l.DidError()
 }
 
+// returns the errors of the lexer, followed by the errors of the parser
 func (p *Parser) Errors() []error {
-	return p.errors
+	return append(slices.Clone(p.l.Errors()), p.errors...)
 }
 
 func (p *Parser) GetErrorPosition(tok token.Token) (int, int) {
@@ -130,19 +176,38 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program.Statements = make([]ast.Stmt, 0)
 
 	for p.curToken.Type != token.EOF {
+		start := p.statementStart()
 		stmt := p.parseStatement()
+		// if curToken starts the next statement
+		next := false
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		} else {
-			p.recover()
+			next = p.recover(start)
+		}
+		if p.tooManyErrors() {
+			p.errorAt(&p.curToken, "too many errors")
+			break
+		}
+		if !next {
+			p.advance()
 		}
-		p.advance()
 	}
 
 	return program
 }
 
 func (p *Parser) peekStickiness() int {
+	// the postfix `value?` sticks like a call
+	if p.peekTokenIs(token.QUESTION) {
+		state := p.save()
+		p.advance()
+		propagate := p.curIsPropagate()
+		p.restore(state)
+		if propagate {
+			return CALL
+		}
+	}
 	if s, ok := stickinessMap[p.peekToken.Type]; ok {
 		return s
 	}
@@ -158,6 +223,12 @@ func (p *Parser) curStickiness() int {
 
 // consume current token
 func (p *Parser) advance() {
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.depth++
+	case token.RBRACE:
+		p.depth--
+	}
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
 }
@@ -205,3 +276,33 @@ func (p *Parser) expectCur(typ token.TokenType) bool {
 	p.expectPeekError(typ)
 	return false
 }
+
+// parserState is a snapshot of the parser used for backtracking
+type parserState struct {
+	lexer     lexer.Lexer
+	curToken  token.Token
+	peekToken token.Token
+	depth     int
+	errors    []error
+}
+
+// takes a snapshot of the parser that can be restored with `restore`
+func (p *Parser) save() parserState {
+	return parserState{
+		lexer:     *p.l,
+		curToken:  p.curToken,
+		peekToken: p.peekToken,
+		depth:     p.depth,
+		errors:    p.errors,
+	}
+}
+
+// rewinds the parser to a snapshot taken with `save`.
+// any errors reported after the snapshot are discarded
+func (p *Parser) restore(state parserState) {
+	*p.l = state.lexer
+	p.curToken = state.curToken
+	p.peekToken = state.peekToken
+	p.depth = state.depth
+	p.errors = state.errors
+}
@@ -3,9 +3,11 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/fredrikkvalvik/temp-lang/pkg/ast"
+	"github.com/fredrikkvalvik/temp-lang/pkg/diagnostic"
 	"github.com/fredrikkvalvik/temp-lang/pkg/lexer"
 	"github.com/fredrikkvalvik/temp-lang/pkg/tester"
 	"github.com/fredrikkvalvik/temp-lang/pkg/token"
@@ -24,6 +26,8 @@ func TestLetStatements(t *testing.T) {
 		{`let fre = "hei"`, "fre", "hei"},
 		{`let      hei_ha         = "hei"`, "hei_ha", "hei"},
 		{`let ___hei = "hei"`, "___hei", "hei"},
+		{"const a = 1", "a", float64(1)},
+		{`const name = "hei"`, "name", "hei"},
 	}
 
 	for i, tt := range tests {
@@ -38,12 +42,49 @@ func TestLetStatements(t *testing.T) {
 			return
 		}
 
+		if isConst := strings.HasPrefix(tt.input, "const"); stmt.(*ast.LetStmt).Const != isConst {
+			t.Fatalf("[t: %d] expected Const=%v, got=%v\n", i, isConst, stmt.(*ast.LetStmt).Const)
+		}
+
 		val := stmt.(*ast.LetStmt).Value
 		if !testLiteralExpression(t, i, val, tt.expectedValue) {
 			return
 		}
 	}
 }
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedPattern string
+	}{
+		{"let [a, b] = list", "[a, b]"},
+		{"let [a, ...rest] = list", "[a, ...rest]"},
+		{"let [...rest] = list", "[...rest]"},
+		{"let [_, [a, b]] = list", "[_, [a, b]]"},
+		{"let {name, age} = person", "{name: name, age: age}"},
+		{`let {"name": n} = person`, `{"name": n}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			l := lexer.New(tt.input)
+			p := New(l)
+
+			res := p.ParseProgram()
+			if p.DidError() {
+				tr.T.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+			}
+			tr.AssertEqual(len(res.Statements), 1)
+
+			let, ok := res.Statements[0].(*ast.LetStmt)
+			tr.AssertTrue(ok)
+			tr.AssertTrue(let.Name == nil, "destructuring let has no name")
+			tr.AssertEqual(let.Pattern.String(), tt.expectedPattern)
+		})
+	}
+}
+
 func testLetStatement(t *testing.T, i int, stmt ast.Stmt, expectedName string) bool {
 	let, ok := stmt.(*ast.LetStmt)
 	if !ok {
@@ -244,6 +285,204 @@ func TestWhileStatement(t *testing.T) {
 	}
 }
 
+func TestTryStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { f() } catch (e) { g(e) }",
+			"try {\nfn f()\n} catch (e) {\nfn g(e)\n}"},
+		{"try {} catch {}",
+			"try {\n} catch {\n}"},
+		{"try {} finally { close() }",
+			"try {\n} finally {\nfn close()\n}"},
+		{"try {} catch (e) {} finally {}",
+			"try {\n} catch (e) {\n} finally {\n}"},
+		{"throw \"boom\"",
+			"throw \"boom\""},
+		{"throw e",
+			"throw e"},
+		{"defer close(f)",
+			"defer fn close(f)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			res := testParseProgram(tt.input)
+
+			tr.AssertEqual(len(res.Statements), 1, "expect a single stmt")
+			tr.AssertEqual(res.Statements[0].String(), tt.expected)
+		})
+	}
+}
+
+func TestInvalidTryStatement(t *testing.T) {
+	tests := []string{
+		"try {}",
+		"try {} catch e {}",
+		"try {} catch (1) {}",
+		"try {} finally",
+		"throw;",
+		"defer;",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			l := lexer.New(input)
+			p := New(l)
+
+			p.ParseProgram()
+			tr.AssertTrue(p.DidError(), "expected parser to error")
+		})
+	}
+}
+
+func TestErrorDiagnostics(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		// errors from the lexer are part of the parser errors
+		{"let a = 1 $", []string{"[1:11] Unexpected character"}},
+		{"let a = (1", []string{"[1:10] expected `RPAREN`, got=`SEMICOLON`"}},
+		{"try {}", []string{"[1:1] Parse error: expected catch or finally after try block"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			l := lexer.New(tt.input)
+			p := New(l)
+
+			p.ParseProgram()
+			errs := []string{}
+			for _, err := range p.Errors() {
+				var d *diagnostic.Diagnostic
+				tr.AssertTrue(errors.As(err, &d), "expected a diagnostic")
+				errs = append(errs, err.Error())
+			}
+			tr.AssertEqual(strings.Join(errs, "\n"), strings.Join(tt.expected, "\n"))
+		})
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedErrors     []string
+		expectedStatements int
+	}{
+		// every bad statement is reported, and the ones after it are parsed
+		{"let = 1\nlet a = 2\nlet b = (3\nprint a;",
+			[]string{"[1:5] expected `IDENT`, got=`ASSIGN`", "[3:11] expected `RPAREN`, got=`SEMICOLON`"}, 2},
+		// errors inside a block don't end the block
+		{"fn f() {\n\tlet = 1\n\treturn 2\n}\nlet a = )\nlet b = f()",
+			[]string{"[2:6] expected `IDENT`, got=`ASSIGN`", "[5:9] could not parser tok=RPAREN"}, 2},
+		// a `;` ends the bad statement
+		{"let a = 1 + ; let b = 2",
+			[]string{"[1:13] could not parser tok=SEMICOLON"}, 1},
+		// recovery stops before a keyword that starts a statement on the same line
+		{"let a = ) + 1 let b = 2",
+			[]string{"[1:9] could not parser tok=RPAREN"}, 1},
+		// blocks are skipped whole
+		{"let a = ) { let b = 1 }\nlet c = 3",
+			[]string{"[1:9] could not parser tok=RPAREN"}, 1},
+		{"if true { } else x\nlet a = 1",
+			[]string{"[1:18] expected `LBRACE`, got=`IDENT`"}, 1},
+		// the token that could not be parsed can start the next statement
+		{"let a =\nlet b = 2",
+			[]string{"[2:1] could not parser tok=LET"}, 1},
+		// the `}` of a literal the bad expression opened is skipped
+		{"let d = {\"a\" b}\nlet e = 1",
+			[]string{"[1:14] expected `COLON`, got=`IDENT`"}, 1},
+		{"let m = match 1 { 1 x }\nlet e = 1",
+			[]string{"[1:21] expected `ARROW`, got=`IDENT`"}, 1},
+		// one diagnostic for an expression that ends too early
+		{"let y = (",
+			[]string{"[1:9] could not parser tok=EOF"}, 0},
+		{"let i = xs[",
+			[]string{"[1:11] could not parser tok=EOF"}, 0},
+		// a bad item ends the list it is in
+		{"print x +;\nfn g() { return 1 }",
+			[]string{"[1:10] could not parser tok=SEMICOLON"}, 1},
+		{"let l = [1, ), 2]\nlet e = 1",
+			[]string{"[1:13] could not parser tok=RPAREN"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			l := lexer.New(tt.input)
+			p := New(l)
+
+			program := p.ParseProgram()
+			errs := []string{}
+			for _, err := range p.Errors() {
+				errs = append(errs, err.Error())
+			}
+			tr.AssertEqual(strings.Join(errs, "\n"), strings.Join(tt.expectedErrors, "\n"))
+			tr.AssertEqual(len(program.Statements), tt.expectedStatements)
+		})
+	}
+}
+
+func TestTooManyErrors(t *testing.T) {
+	tr := tester.New(t, "")
+	l := lexer.New(strings.Repeat("let = 1\n", maxErrors*2))
+	p := New(l)
+
+	p.ParseProgram()
+	errs := p.Errors()
+	tr.AssertEqual(len(errs), maxErrors+1)
+	tr.AssertEqual(errs[maxErrors].Error(), "[10:8] too many errors")
+}
+
+func TestIterStatement(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedName     string
+		expectedIterable string
+	}{
+		{"each items {}",
+			"", "items"},
+		{"each [1, 2] {}",
+			"", "[1, 2]"},
+		{"each item : items {}",
+			"item", "items"},
+		{"each [key, value] : pairs {}",
+			"[key, value]", "pairs"},
+		{"each [head, ...tail] : [[1, 2]] {}",
+			"[head, ...tail]", "[[1, 2]]"},
+		{"each {name} : people {}",
+			"{name: name}", "people"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			l := lexer.New(tt.input)
+			p := New(l)
+
+			res := p.ParseProgram()
+			if p.DidError() {
+				tr.T.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+			}
+			tr.AssertEqual(len(res.Statements), 1)
+
+			each, ok := res.Statements[0].(*ast.IterStmt)
+			tr.AssertTrue(ok)
+			if tt.expectedName == "" {
+				tr.AssertNil(each.Name)
+			} else {
+				tr.AssertEqual(each.Name.String(), tt.expectedName)
+			}
+			tr.AssertEqual(each.Iterable.String(), tt.expectedIterable)
+		})
+	}
+}
+
 func TestFunctionLiterals(t *testing.T) {
 
 	tests := []struct {
@@ -288,6 +527,167 @@ func TestFunctionLiterals(t *testing.T) {
 		tr.AssertEqual(len(fun.Body.Statements), tt.expectedBodyLen)
 	}
 }
+func TestFunctionParameters(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{"fn f(a, b) {}",
+			[]string{"a", "b"}},
+		{"fn f(a, b = 2) {}",
+			[]string{"a", "b = 2"}},
+		{"fn f(a = 1, b = a + 1) {}",
+			[]string{"a = 1", "b = (a + 1)"}},
+		{"fn f(first, ...rest) {}",
+			[]string{"first", "...rest"}},
+		{"fn f(a, b = 2, ...rest) {}",
+			[]string{"a", "b = 2", "...rest"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			l := lexer.New(tt.input)
+			p := New(l)
+
+			res := p.ParseProgram()
+			if p.DidError() {
+				tr.T.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+			}
+			tr.AssertEqual(len(res.Statements), 1)
+
+			let, ok := res.Statements[0].(*ast.LetStmt)
+			tr.AssertTrue(ok)
+
+			fun, ok := let.Value.(*ast.FunctionLiteralExpr)
+			tr.AssertTrue(ok)
+			tr.AssertEqual(fun.Name, "f")
+
+			tr.AssertEqual(len(fun.Arguments), len(tt.expectedParams))
+			for idx, param := range fun.Arguments {
+				tr.AssertEqual(param.String(), tt.expectedParams[idx])
+			}
+		})
+	}
+}
+
+func TestLambdaExpressions(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+		expectedBody   string
+	}{
+		{"x => x * 2",
+			[]string{"x"}, "(x * 2)"},
+		{"(x) => x",
+			[]string{"x"}, "x"},
+		{"() => 1",
+			[]string{}, "1"},
+		{"(a, b) => a + b",
+			[]string{"a", "b"}, "(a + b)"},
+		{"(a, b = 2, ...rest) => a",
+			[]string{"a", "b = 2", "...rest"}, "a"},
+		{"a => b => a + b",
+			[]string{"a"}, "fn(b) {\nreturn (a + b)}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			l := lexer.New(tt.input)
+			p := New(l)
+
+			res := p.ParseProgram()
+			if p.DidError() {
+				tr.T.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+			}
+			tr.AssertEqual(len(res.Statements), 1)
+
+			expression, ok := res.Statements[0].(*ast.ExpressionStmt)
+			tr.AssertTrue(ok)
+
+			fun, ok := expression.Expression.(*ast.FunctionLiteralExpr)
+			tr.AssertTrue(ok)
+
+			tr.AssertEqual(len(fun.Arguments), len(tt.expectedParams))
+			for idx, param := range fun.Arguments {
+				tr.AssertEqual(param.String(), tt.expectedParams[idx])
+			}
+
+			tr.AssertEqual(len(fun.Body.Statements), 1)
+			ret, ok := fun.Body.Statements[0].(*ast.ReturnStmt)
+			tr.AssertTrue(ok)
+			tr.AssertEqual(ret.Value.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestGeneratorFunctions(t *testing.T) {
+	tests := []struct {
+		input     string
+		generator bool
+	}{
+		{"let g = fn() { yield 1 }", true},
+		{"let g = fn() { yield }", true},
+		{"let g = fn() { if true { yield 1 } }", true},
+		{"let g = fn() { each x : xs { yield x } }", true},
+		{"let g = fn() { return 1 }", false},
+		// a yield belongs to the innermost function
+		{"let g = fn() { let f = fn() { yield 1 } }", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res := testParseProgram(tt.input)
+			tr.AssertEqual(len(res.Statements), 1)
+
+			let, ok := res.Statements[0].(*ast.LetStmt)
+			tr.AssertTrue(ok)
+			fun, ok := let.Value.(*ast.FunctionLiteralExpr)
+			tr.AssertTrue(ok)
+
+			tr.AssertEqual(fun.Generator, tt.generator)
+		})
+	}
+
+	t.Run("function statement", func(t *testing.T) {
+		tr := tester.New(t, "")
+
+		res := testParseProgram("fn gen() {\n\tyield 1\n\tyield 2\n}")
+		tr.AssertEqual(len(res.Statements), 1)
+
+		let, ok := res.Statements[0].(*ast.LetStmt)
+		tr.AssertTrue(ok)
+		fun := let.Value.(*ast.FunctionLiteralExpr)
+		tr.AssertTrue(fun.Generator)
+		tr.AssertEqual(len(fun.Body.Statements), 2)
+		tr.AssertEqual(fun.Body.Statements[0].String(), "yield 1")
+	})
+}
+
+func TestInvalidFunctionParameters(t *testing.T) {
+	tests := []string{
+		"fn f(...rest, a) {}",
+		"fn f(a = 1, b) {}",
+		"fn f(1) {}",
+		"(1 + 2) => 3",
+		"(a, 1) => a",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			l := lexer.New(input)
+			p := New(l)
+
+			p.ParseProgram()
+			tr.AssertTrue(p.DidError(), "expected parser to error")
+		})
+	}
+}
+
 func TestFunctionCalls(t *testing.T) {
 
 	// TODO: can probalby improve test by testing the arguments of
@@ -314,6 +714,12 @@ func TestFunctionCalls(t *testing.T) {
 		// anynomous function call with args
 		{"(fn(a,b,c) {})(1,2,3)",
 			"fn(a, b, c) {\n}", 3},
+		// named args
+		{"function(1, b: 2, c: 3)",
+			"function", 3},
+		// spread args
+		{"function(1, ...rest)",
+			"function", 2},
 	}
 
 	for idx, tt := range tests {
@@ -342,6 +748,244 @@ func TestFunctionCalls(t *testing.T) {
 	}
 }
 
+func TestIfExpressions(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedElse bool
+	}{
+		{"let x = if a { 1 }", false},
+		{"let x = if a { 1 } else { 2 }", true},
+		{"let x = if a { 1 } else if b { 2 } else { 3 }", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res := testParseProgram(tt.input)
+			tr.AssertEqual(len(res.Statements), 1)
+
+			let, ok := res.Statements[0].(*ast.LetStmt)
+			tr.AssertTrue(ok)
+
+			ifExpr, ok := let.Value.(*ast.IfExpr)
+			tr.AssertTrue(ok)
+			tr.AssertEqual(ifExpr.Condition.String(), "a")
+			tr.AssertEqual(len(ifExpr.Then.Statements), 1)
+			tr.AssertEqual(ifExpr.Else != nil, tt.expectedElse)
+		})
+	}
+}
+
+func TestTernaryExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a ? b : c",
+			"(a ? b : c)"},
+		{"a > 1 ? b + 1 : c",
+			"((a > 1) ? (b + 1) : c)"},
+		{"a ? b : c ? d : e",
+			"(a ? b : (c ? d : e))"},
+		{"a and b ? c : d",
+			"((a and b) ? c : d)"},
+		{"x = a ? b : c",
+			"(x = (a ? b : c))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res := testParseProgram(tt.input)
+			tr.AssertEqual(len(res.Statements), 1)
+
+			expression, ok := res.Statements[0].(*ast.ExpressionStmt)
+			tr.AssertTrue(ok)
+
+			tr.AssertEqual(expression.Expression.String(), tt.expected)
+		})
+	}
+}
+
+func TestPropagateExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"f()?",
+			"(fn f()?)"},
+		{"a + f()?",
+			"(a + (fn f()?))"},
+		{"f()? * 2",
+			"((fn f()?) * 2)"},
+		{"g(f()?, x?)",
+			"fn g((fn f()?), (x?))"},
+		{"xs[0]?",
+			"(xs[0]?)"},
+		{"a ? f()? : c",
+			"(a ? (fn f()?) : c)"},
+		// it is a ternary only when an expression and a `:` follows the `?`
+		{"f()? - 1",
+			"((fn f()?) - 1)"},
+		{"a ? -1 : 2",
+			"(a ? (-1) : 2)"},
+		{"f()? and true",
+			"((fn f()?) and true)"},
+		{"f()? or g()?",
+			"((fn f()?) or (fn g()?))"},
+		{"a? ? b : c",
+			"((a?) ? b : c)"},
+		{"a ? b ? c : d : e",
+			"(a ? (b ? c : d) : e)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res := testParseProgram(tt.input)
+			tr.AssertEqual(len(res.Statements), 1)
+
+			expression, ok := res.Statements[0].(*ast.ExpressionStmt)
+			tr.AssertTrue(ok)
+
+			tr.AssertEqual(expression.Expression.String(), tt.expected)
+		})
+	}
+}
+
+func TestPropagateBeforeBlock(t *testing.T) {
+	tr := tester.New(t, "")
+	l := lexer.New("each x : items()? { print x; }")
+	p := New(l)
+
+	res := p.ParseProgram()
+	if p.DidError() {
+		tr.T.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+	}
+	tr.AssertEqual(len(res.Statements), 1)
+
+	each, ok := res.Statements[0].(*ast.IterStmt)
+	tr.AssertTrue(ok)
+	_, ok = each.Iterable.(*ast.PropagateExpr)
+	tr.AssertTrue(ok, "iterable is a propagate expression")
+}
+
+func TestOptionalChaining(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a?.b",
+			"a?.b"},
+		{`a?["b"]`,
+			`a?["b"]`},
+		{`a["b"]?["c"]?.d`,
+			`a["b"]?["c"]?.d`},
+		{"a ?? b",
+			"(a ?? b)"},
+		{"a ?? b ?? c",
+			"((a ?? b) ?? c)"},
+		{`a?["b"] ?? 1 + 2`,
+			`(a?["b"] ?? (1 + 2))`},
+		{"a ?? b ? c : d",
+			"((a ?? b) ? c : d)"},
+		// with a space before `?[` it is a ternary
+		{"c ?[1] : [2]",
+			"(c ? [1] : [2])"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res := testParseProgram(tt.input)
+			tr.AssertEqual(len(res.Statements), 1)
+
+			expression, ok := res.Statements[0].(*ast.ExpressionStmt)
+			tr.AssertTrue(ok)
+
+			tr.AssertEqual(expression.Expression.String(), tt.expected)
+		})
+	}
+}
+
+func TestRangeExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0..10",
+			"(0..10)"},
+		{"0..=10",
+			"(0..=10)"},
+		{"10..0 step 2",
+			"(10..0 step 2)"},
+		{"a + 1..b * 2 step c - 1",
+			"((a + 1)..(b * 2) step (c - 1))"},
+		{"0..len(xs)",
+			"(0..fn len(xs))"},
+		{"step..step step step",
+			"(step..step step step)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res := testParseProgram(tt.input)
+			tr.AssertEqual(len(res.Statements), 1)
+
+			expression, ok := res.Statements[0].(*ast.ExpressionStmt)
+			tr.AssertTrue(ok)
+
+			tr.AssertEqual(expression.Expression.String(), tt.expected)
+		})
+	}
+}
+
+func TestPipeExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"xs |> f()",
+			"fn f(xs)"},
+		{"xs |> f",
+			"fn f(xs)"},
+		{"xs |> f(1, 2)",
+			"fn f(xs, 1, 2)"},
+		{"xs |> f(a) |> g()",
+			"fn g(fn f(xs, a))"},
+		{"1 + 2 |> f()",
+			"fn f((1 + 2))"},
+		{"xs |> len() > 2",
+			"(fn len(xs) > 2)"},
+		{"xs |> sum() + 1",
+			"(fn sum(xs) + 1)"},
+		{"xs |> sum() * 2 |> f()",
+			"fn f((fn sum(xs) * 2))"},
+		{"xs\n\t|> f()\n\t|> g()",
+			"fn g(fn f(xs))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res := testParseProgram(tt.input)
+			tr.AssertEqual(len(res.Statements), 1)
+
+			expression, ok := res.Statements[0].(*ast.ExpressionStmt)
+			tr.AssertTrue(ok)
+
+			tr.AssertEqual(expression.Expression.String(), tt.expected)
+		})
+	}
+}
+
 func TestListLiteralExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -353,6 +997,8 @@ func TestListLiteralExpressions(t *testing.T) {
 			[]any{float64(1)}},
 		{`[1, "hello", "world"]`,
 			[]any{float64(1), "hello", "world"}},
+		{"[...a, 1, ...b]",
+			[]any{"...a", float64(1), "...b"}},
 	}
 
 	for _, tt := range tests {
@@ -369,6 +1015,13 @@ func TestListLiteralExpressions(t *testing.T) {
 			tr.AssertTrue(ok)
 
 			tr.AssertEqual(len(listLit.Items), len(tt.expected))
+			for idx, item := range listLit.Items {
+				if spread, ok := tt.expected[idx].(string); ok && strings.HasPrefix(spread, "...") {
+					_, ok := item.(*ast.SpreadExpr)
+					tr.AssertTrue(ok, "expected spread, got "+item.String())
+					tr.AssertEqual(item.String(), spread)
+				}
+			}
 		})
 	}
 }
@@ -409,6 +1062,37 @@ func TestMapLiteralExpressions(t *testing.T) {
 	}
 }
 
+func TestMapLiteralSpread(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`({...defaults})`,
+			`{...defaults}`},
+		{`({...defaults, "k": 1})`,
+			`{...defaults, "k": 1}`},
+		{`({"k": 1, ...a, ...b})`,
+			`{"k": 1, ...a, ...b}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res := testParseProgram(tt.input)
+			tr.AssertEqual(len(res.Statements), 1)
+
+			expression, ok := res.Statements[0].(*ast.ExpressionStmt)
+			tr.AssertTrue(ok)
+
+			mapLit, ok := expression.Expression.(*ast.ParenExpr).Expression.(*ast.MapLiteralExpr)
+			tr.AssertTrue(ok)
+
+			tr.AssertEqual(mapLit.String(), tt.expected)
+		})
+	}
+}
+
 func TestImportStmt(t *testing.T) {
 	tests := []struct {
 		input        string
@@ -489,6 +1173,90 @@ func TestIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:3]", "a[1:3]"},
+		{"a[:-1]", "a[:(-1)]"},
+		{"a[2:]", "a[2:]"},
+		{"a[:]", "a[:]"},
+		{"a[::2]", "a[::2]"},
+		{"a[1:5:2]", "a[1:5:2]"},
+		{"a[::-1]", "a[::(-1)]"},
+		{"a[i + 1:len(a)]", "a[(i + 1):fn len(a)]"},
+		{"a?[1:]", "a?[1:]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			l := lexer.New(tt.input)
+			p := New(l)
+
+			res := p.ParseProgram()
+			if p.DidError() {
+				tr.T.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+			}
+			tr.AssertEqual(len(res.Statements), 1)
+
+			expression, ok := res.Statements[0].(*ast.ExpressionStmt)
+			tr.AssertTrue(ok)
+
+			indexExpr, ok := expression.Expression.(*ast.IndexExpr)
+			tr.AssertTrue(ok)
+			tr.AssertTrue(indexExpr.Slice)
+
+			tr.AssertEqual(indexExpr.String(), tt.expected)
+		})
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedArms     int
+		expectedPatterns []string
+	}{
+		{`match x { 1 => "one", _ => "other" }`,
+			2, []string{"1", "_"}},
+		{`match x {
+			[a, b] => a
+			{"key": v, name} if v => v
+			-1 => { x }
+		}`,
+			3, []string{"[a, b]", `{"key": v, name: name}`, "(-1)"}},
+		{`match x {}`,
+			0, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+			l := lexer.New(tt.input)
+			p := New(l)
+
+			res := p.ParseProgram()
+			if p.DidError() {
+				tr.T.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+			}
+			tr.AssertEqual(len(res.Statements), 1)
+
+			expression, ok := res.Statements[0].(*ast.ExpressionStmt)
+			tr.AssertTrue(ok)
+
+			match, ok := expression.Expression.(*ast.MatchExpr)
+			tr.AssertTrue(ok)
+
+			tr.AssertEqual(len(match.Arms), tt.expectedArms)
+			for idx, arm := range match.Arms {
+				tr.AssertEqual(arm.Pattern.String(), tt.expectedPatterns[idx])
+			}
+		})
+	}
+}
+
 func testBinaryExpression(t *testing.T, i int, expr *ast.BinaryExpr, eLeft any, op token.TokenType, eRight any) bool {
 	t.Helper()
 	if !testLiteralExpression(t, i, expr.Left, eLeft) {
@@ -5,31 +5,40 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/fredrikkvalvik/temp-lang/pkg/diagnostic"
 	"github.com/fredrikkvalvik/temp-lang/pkg/evaluator"
 	"github.com/fredrikkvalvik/temp-lang/pkg/lexer"
 	"github.com/fredrikkvalvik/temp-lang/pkg/object"
 	"github.com/fredrikkvalvik/temp-lang/pkg/parser"
 	"github.com/fredrikkvalvik/temp-lang/pkg/resolver"
+	"github.com/fredrikkvalvik/temp-lang/pkg/token"
 )
 
 type Repl struct {
 	// env *object.Environment
 	in  io.Reader
 	out io.Writer
+	// every line read is added to files as "<repl>"
+	files *token.FileSet
 }
 
-func New(in io.Reader, out io.Writer) *Repl {
+func New(in io.Reader, out io.Writer, files *token.FileSet) *Repl {
 	return &Repl{
-		in:  in,
-		out: out,
+		in:    in,
+		out:   out,
+		files: files,
 	}
 }
 
 // os.Stdin and os.Stdout are the usual args
 func (r *Repl) Run(env *object.Environment) {
 	s := bufio.NewScanner(r.in)
+	if env.Context() == nil {
+		env.SetContext(evaluator.NewInterpreter(r.out))
+	}
 
 	resolve := resolver.New(env)
+	color := diagnostic.IsTerminal(r.out)
 	for {
 		fmt.Print("> ")
 		scanned := s.Scan()
@@ -38,34 +47,28 @@ func (r *Repl) Run(env *object.Environment) {
 		}
 
 		line := s.Text()
-		l := lexer.New(line)
-		if l.DidError() {
-			for _, err := range l.Errors() {
-				fmt.Fprintf(r.out, "%s\n", err)
-			}
-			continue
-		}
-
+		l := lexer.NewFromSource(r.files.Add("<repl>", line))
 		p := parser.New(l)
 		program := p.ParseProgram()
 
+		if p.DidError() {
+			diagnostic.RenderAll(r.out, p.Errors(), color)
+			continue
+		}
+
 		resolve.Resolve(program)
 		if len(resolve.Errors) > 0 {
-			for _, err := range resolve.Errors {
-				fmt.Printf("err: %v\n", err)
-			}
+			diagnostic.RenderAll(r.out, resolve.Errors, color)
 			resolve.Errors = []error{}
 			continue
 		}
 
-		if p.DidError() {
-			for _, err := range p.Errors() {
-				fmt.Println(err.Error())
-			}
+		result := evaluator.Eval(program, env)
+		if err, ok := result.(*object.ErrorObj); ok {
+			err.Diagnostic().Render(r.out, color)
+			fmt.Fprint(r.out, err.StackTrace())
 			continue
 		}
-
-		result := evaluator.Eval(program, env)
 		fmt.Printf("%s\n", result.Inspect())
 	}
 }
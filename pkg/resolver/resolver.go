@@ -14,8 +14,10 @@ import (
 	"slices"
 
 	"github.com/fredrikkvalvik/temp-lang/pkg/ast"
+	"github.com/fredrikkvalvik/temp-lang/pkg/diagnostic"
 	"github.com/fredrikkvalvik/temp-lang/pkg/object"
 	"github.com/fredrikkvalvik/temp-lang/pkg/std/fmt_std"
+	"github.com/fredrikkvalvik/temp-lang/pkg/std/iter_std"
 	"github.com/fredrikkvalvik/temp-lang/pkg/token"
 )
 
@@ -28,24 +30,47 @@ const (
 )
 
 var (
-	IllegalDeclarationError             = errors.New("Can't a declare two variables with the same name in the same scope")
-	IllegalDefinitionError              = errors.New("Can't define variable that is not declared")
-	IllegalUseOfSelfInitError           = errors.New("Can't read local variable in its own initializer")
-	IllegalReturnOutsideFunctionError   = errors.New("Can't return outside function body")
-	IllegalScopedImportError            = errors.New("Can only import in global scope")
-	IllegalImportAfterDeclarationsError = errors.New("Can only import at the beginning of the file")
+	IllegalDeclarationError              = errors.New("Can't a declare two variables with the same name in the same scope")
+	IllegalDefinitionError               = errors.New("Can't define variable that is not declared")
+	IllegalUseOfSelfInitError            = errors.New("Can't read local variable in its own initializer")
+	IllegalReturnOutsideFunctionError    = errors.New("Can't return outside function body")
+	IllegalScopedImportError             = errors.New("Can only import in global scope")
+	IllegalImportAfterDeclarationsError  = errors.New("Can only import at the beginning of the file")
+	IllegalDuplicateBindingError         = errors.New("Can't bind the same name twice in a pattern")
+	IllegalConstAssignmentError          = errors.New("Can't assign to a constant")
+	IllegalPropagateOutsideFunctionError = errors.New("Can't use `?` outside function body")
+	IllegalDeferOutsideFunctionError     = errors.New("Can't defer outside function body")
+	IllegalYieldOutsideFunctionError     = errors.New("Can't yield outside function body")
 
 	// error for development. should only be returned when the resolver has not implemented a resolve-case for a node
 	UnknownNodeError = errors.New("Resolution for node not implemented")
 )
 
-func (r *Resolver) newError(pos token.Pos, err error) {
-	r.Errors = append(r.Errors, fmt.Errorf("%s %w", pos, err))
+// function collects what is needed to decide if the returned calls of a
+// function body can be made as tail calls. A call in `return f(x)` is a tail
+// call only when nothing is left to do in the function after it returns, so
+// not inside a try, not in a function with a defer, and not in a generator
+type function struct {
+	tailCalls []*ast.CallExpr
+	// a call inside a try statement has to return before the handlers run
+	tryDepth int
+	// deferred expressions run after the returned call has finished
+	hasDefer bool
+	// the returned value of a generator is dropped
+	generator bool
+}
+
+// adds a diagnostic at pos. The diagnostic is returned so hints can be added to it
+func (r *Resolver) newError(pos token.Pos, err error) *diagnostic.Diagnostic {
+	d := diagnostic.New(pos, err)
+	r.Errors = append(r.Errors, d)
+	return d
 }
 
 // if importPath == stdPath, resolve import to stdLib, else resolve to file path
 var stdModules = map[string]*object.ModuleObj{
-	"fmt": &fmt_std.Module,
+	"fmt":  &fmt_std.Module,
+	"iter": &iter_std.Module,
 }
 
 type Resolver struct {
@@ -53,6 +78,14 @@ type Resolver struct {
 	scopeType Stack[ScopeType]
 	globalEnv *object.Environment
 
+	// constants mirrors scope, and marks which of the declared names are constants.
+	// global declarations are not part of scope, so they are tracked separately
+	constants       Stack[map[string]bool]
+	globalConstants map[string]bool
+
+	// functions holds the function bodies being resolved, innermost last
+	functions Stack[*function]
+
 	// we are done parsing imports when we resolve any other stmt.
 	// imports need to be at the top of the file
 	// doneResolvingImports bool
@@ -64,6 +97,9 @@ func New(env *object.Environment) *Resolver {
 	r := &Resolver{
 		scope:     Stack[map[string]bool]{},
 		globalEnv: env,
+
+		constants:       Stack[map[string]bool]{},
+		globalConstants: map[string]bool{},
 	}
 
 	return r
@@ -75,6 +111,7 @@ func (r *Resolver) Resolve(node ast.Node) {
 	case *ast.Program:
 		r.resolveImports()
 		r.hoistFunctions(n)
+		r.markGlobalConstants(n)
 
 		r.resolveStmtList(n.Statements)
 		return
@@ -124,16 +161,60 @@ func (r *Resolver) Resolve(node ast.Node) {
 		r.popScopeType()
 
 	case *ast.IterStmt:
-		if n.Name != nil {
-			r.Resolve(n.Name)
-		}
 		if n.Iterable != nil {
 			r.Resolve(n.Iterable)
 		}
 		r.pushScopeType(IterScope)
-		r.Resolve(n.Body)
+		// the loop variables are declared in the same scope as the body
+		r.enterScope()
+		if n.Name != nil {
+			r.declarePattern(n.Name)
+			r.definePattern(n.Name)
+		}
+		r.resolveStmtList(n.Body.Statements)
+		r.leaveScope()
 		r.popScopeType()
 
+	case *ast.TryStmt:
+		if !r.functions.IsEmpty() {
+			r.functions.Peek().tryDepth++
+			defer func() { r.functions.Peek().tryDepth-- }()
+		}
+		r.Resolve(n.Body)
+		if n.Catch != nil {
+			// the caught error is declared in the same scope as the catch body
+			r.enterScope()
+			if n.Param != nil {
+				r.declare(n.Param.Value)
+				r.define(n.Param.Value)
+			}
+			r.resolveStmtList(n.Catch.Statements)
+			r.leaveScope()
+		}
+		if n.Finally != nil {
+			r.Resolve(n.Finally)
+		}
+
+	case *ast.ThrowStmt:
+		r.Resolve(n.Value)
+
+	case *ast.DeferStmt:
+		if !r.hasScopeType(FunctionScope) {
+			r.newError(n.Token.Pos, IllegalDeferOutsideFunctionError)
+		}
+		if !r.functions.IsEmpty() {
+			r.functions.Peek().hasDefer = true
+		}
+		r.Resolve(n.Value)
+
+	case *ast.YieldStmt:
+		if !r.hasScopeType(FunctionScope) {
+			r.newError(n.Token.Pos, IllegalYieldOutsideFunctionError)
+		}
+		if n.Value != nil {
+			r.Resolve(n.Value)
+		}
+
 	case *ast.PrintStmt:
 		for _, expr := range n.Expressions {
 			r.Resolve(expr)
@@ -148,16 +229,30 @@ func (r *Resolver) Resolve(node ast.Node) {
 		if n.Value != nil {
 			r.Resolve(n.Value)
 		}
+		if call, ok := n.Value.(*ast.CallExpr); ok && !r.functions.IsEmpty() {
+			if fun := r.functions.Peek(); fun.tryDepth == 0 {
+				fun.tailCalls = append(fun.tailCalls, call)
+			}
+		}
 
 	case *ast.LetStmt:
-		if _, ok := n.Value.(*ast.FunctionLiteralExpr); ok {
+		if n.Pattern != nil {
+			r.declarePattern(n.Pattern)
+			r.Resolve(n.Value)
+			r.definePattern(n.Pattern)
+			for _, name := range r.patternBindings(n.Pattern) {
+				r.markConstant(name.Value, n.Const)
+			}
+		} else if _, ok := n.Value.(*ast.FunctionLiteralExpr); ok {
 			r.declare(n.Name.Value)
 			r.define(n.Name.Value)
+			r.markConstant(n.Name.Value, n.Const)
 			r.Resolve(n.Value)
 		} else {
 			r.declare(n.Name.Value)
 			r.Resolve(n.Value)
 			r.define(n.Name.Value)
+			r.markConstant(n.Name.Value, n.Const)
 		}
 
 	case *ast.IdentifierExpr:
@@ -186,18 +281,35 @@ func (r *Resolver) Resolve(node ast.Node) {
 	case *ast.AssignExpr:
 		r.Resolve(n.Value)
 		r.Resolve(n.Assignee)
+		if ident, ok := n.Assignee.(*ast.IdentifierExpr); ok && r.isConstant(ident.Value) {
+			r.newError(ident.Token.Pos, fmt.Errorf("%w `%s`", IllegalConstAssignmentError, ident.Value)).
+				WithHint("declare `%s` with `let` to make it assignable", ident.Value)
+		}
 
 	case *ast.FunctionLiteralExpr:
 		r.enterScope()
 		r.pushScopeType(FunctionScope)
+		r.functions.Push(&function{generator: n.Generator})
 
-		for _, name := range n.Arguments {
-			r.declare(name.Value)
-			r.define(name.Value)
+		for _, param := range n.Arguments {
+			// defaults can refer to the parameters declared before them
+			if param.Default != nil {
+				r.Resolve(param.Default)
+			}
+			r.declare(param.Name.Value)
+			r.define(param.Name.Value)
 		}
 
 		r.resolveStmtList(n.Body.Statements)
 
+		// a defer can come after the return, so the calls are marked
+		// when the whole body is resolved
+		if fun := r.functions.Pop(); !fun.hasDefer && !fun.generator {
+			for _, call := range fun.tailCalls {
+				call.Tail = true
+			}
+		}
+
 		r.leaveScope()
 		r.popScopeType()
 
@@ -208,18 +320,69 @@ func (r *Resolver) Resolve(node ast.Node) {
 		r.Resolve(n.Callee)
 		r.resolveExprList(n.Arguments)
 
+	case *ast.NamedArgumentExpr:
+		r.Resolve(n.Value)
+
 	case *ast.ListLiteralExpr:
 		r.resolveExprList(n.Items)
 
 	case *ast.MapLiteralExpr:
-		for key, value := range n.KeyValues {
-			r.Resolve(key)
-			r.Resolve(value)
+		for _, kv := range n.KeyValues {
+			if kv.Key != nil {
+				r.Resolve(kv.Key)
+			}
+			r.Resolve(kv.Value)
 		}
 
+	case *ast.SpreadExpr:
+		r.Resolve(n.Value)
+
 	case *ast.IndexExpr:
 		r.Resolve(n.Left)
-		r.Resolve(n.Index)
+		for _, expr := range []ast.Expr{n.Index, n.End, n.Step} {
+			if expr != nil {
+				r.Resolve(expr)
+			}
+		}
+
+	case *ast.IfExpr:
+		r.Resolve(n.Condition)
+		r.Resolve(n.Then)
+		if n.Else != nil {
+			r.Resolve(n.Else)
+		}
+
+	case *ast.RangeExpr:
+		r.Resolve(n.Start)
+		r.Resolve(n.End)
+		if n.Step != nil {
+			r.Resolve(n.Step)
+		}
+
+	case *ast.PropagateExpr:
+		if !r.hasScopeType(FunctionScope) {
+			r.newError(n.Token.Pos, IllegalPropagateOutsideFunctionError)
+		}
+		r.Resolve(n.Value)
+
+	case *ast.TernaryExpr:
+		r.Resolve(n.Condition)
+		r.Resolve(n.Then)
+		r.Resolve(n.Else)
+
+	case *ast.MatchExpr:
+		r.Resolve(n.Subject)
+		for _, arm := range n.Arms {
+			// bindings in the pattern are only visible in the guard and body of the arm
+			r.enterScope()
+			r.declarePattern(arm.Pattern)
+			r.definePattern(arm.Pattern)
+			if arm.Guard != nil {
+				r.Resolve(arm.Guard)
+			}
+			r.Resolve(arm.Body)
+			r.leaveScope()
+		}
 
 	case *ast.StringLiteralExpr:
 	case *ast.NumberLiteralExpr:
@@ -230,6 +393,57 @@ func (r *Resolver) Resolve(node ast.Node) {
 	}
 }
 
+// declares every variable bound by the pattern in the current scope.
+// A pattern can only bind the same name once
+func (r *Resolver) declarePattern(pattern ast.Pattern) {
+	seen := map[string]bool{}
+	for _, name := range r.patternBindings(pattern) {
+		if seen[name.Value] {
+			r.newError(name.Token.Pos, IllegalDuplicateBindingError)
+		}
+		seen[name.Value] = true
+		r.declare(name.Value)
+	}
+}
+
+// defines every variable bound by the pattern in the current scope
+func (r *Resolver) definePattern(pattern ast.Pattern) {
+	for _, name := range r.patternBindings(pattern) {
+		r.define(name.Value)
+	}
+}
+
+// returns the variables bound by the pattern in the order they appear.
+// patterns only hold literal expressions, so there is nothing else to resolve
+func (r *Resolver) patternBindings(pattern ast.Pattern) []*ast.IdentifierExpr {
+	switch p := pattern.(type) {
+	case *ast.WildcardPattern:
+		return nil
+	case *ast.BindingPattern:
+		return []*ast.IdentifierExpr{p.Name}
+	case *ast.LiteralPattern:
+		return nil
+	case *ast.ListPattern:
+		names := []*ast.IdentifierExpr{}
+		for _, elem := range p.Elements {
+			names = append(names, r.patternBindings(elem)...)
+		}
+		if p.Rest != nil {
+			names = append(names, r.patternBindings(p.Rest)...)
+		}
+		return names
+	case *ast.MapPattern:
+		names := []*ast.IdentifierExpr{}
+		for _, value := range p.Values {
+			names = append(names, r.patternBindings(value)...)
+		}
+		return names
+	default:
+		r.Errors = append(r.Errors, fmt.Errorf("%w: %T", UnknownNodeError, p))
+		return nil
+	}
+}
+
 func (r *Resolver) resolveLocal(n *ast.IdentifierExpr) {
 	for i := r.scope.Size() - 1; i >= 0; i-- {
 		if _, ok := r.scope[i][n.Value]; ok {
@@ -239,6 +453,26 @@ func (r *Resolver) resolveLocal(n *ast.IdentifierExpr) {
 	}
 }
 
+// records if name, declared in the current scope, is a constant
+func (r *Resolver) markConstant(name string, isConst bool) {
+	if r.constants.IsEmpty() {
+		r.globalConstants[name] = isConst
+		return
+	}
+	r.constants.Peek()[name] = isConst
+}
+
+// reports if the closest declaration of name is a constant
+func (r *Resolver) isConstant(name string) bool {
+	for i := r.scope.Size() - 1; i >= 0; i-- {
+		if _, ok := r.scope[i][name]; ok {
+			return r.constants[i][name]
+		}
+	}
+
+	return r.globalConstants[name]
+}
+
 func (r *Resolver) resolveExprList(list []ast.Expr) {
 	for _, n := range list {
 		r.Resolve(n)
@@ -270,12 +504,14 @@ func (r *Resolver) define(name string) {
 // we look through the stack and check if one of the scopeTypes are in fact a function scope
 func (r *Resolver) enterScope() {
 	r.scope.Push(map[string]bool{})
+	r.constants.Push(map[string]bool{})
 	// r.scopeType.Push(scopeType)
 }
 
 // leave the current scope
 func (r *Resolver) leaveScope() {
 	r.scope.Pop()
+	r.constants.Pop()
 	// r.scopeType.Pop()
 }
 
@@ -322,5 +558,25 @@ func (r *Resolver) hoistFunctions(program *ast.Program) {
 
 	program.Statements = append(functions, programStmts...)
 }
+
+// global constants are marked before resolving, so hoisted functions
+// can't assign to a constant declared further down in the file
+func (r *Resolver) markGlobalConstants(program *ast.Program) {
+	for _, stmt := range program.Statements {
+		let, ok := stmt.(*ast.LetStmt)
+		if !ok || !let.Const {
+			continue
+		}
+
+		if let.Pattern != nil {
+			for _, name := range r.patternBindings(let.Pattern) {
+				r.globalConstants[name.Value] = true
+			}
+		} else {
+			r.globalConstants[let.Name.Value] = true
+		}
+	}
+}
+
 func (r *Resolver) resolveImports() {
 }
@@ -0,0 +1,265 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fredrikkvalvik/temp-lang/pkg/ast"
+	"github.com/fredrikkvalvik/temp-lang/pkg/lexer"
+	"github.com/fredrikkvalvik/temp-lang/pkg/object"
+	"github.com/fredrikkvalvik/temp-lang/pkg/parser"
+	"github.com/fredrikkvalvik/temp-lang/pkg/tester"
+)
+
+func TestConstAssignment(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr error
+	}{
+		{"const a = 1\na = 2",
+			IllegalConstAssignmentError},
+		{"const a = 1\nfn f() {\n\ta = 2\n}",
+			IllegalConstAssignmentError},
+		{"fn f() {\n\ta = 2\n}\nconst a = 1",
+			IllegalConstAssignmentError},
+		{"fn f() {\n\tconst a = 1\n\ta = 2\n}",
+			IllegalConstAssignmentError},
+		{"const [a, b] = [1, 2]\nb = 3",
+			IllegalConstAssignmentError},
+		{"const {name} = {\"name\": 1}\nname = 3",
+			IllegalConstAssignmentError},
+
+		// shadowing a constant with a variable is allowed
+		{"const a = 1\nfn f() {\n\tlet a = 2\n\ta = 3\n}",
+			nil},
+		{"const a = 1\nfn f(a) {\n\ta = 3\n}",
+			nil},
+		{"let a = 1\na = 2",
+			nil},
+		{"const e = 1\ntry {} catch (e) {\n\te = 2\n}",
+			nil},
+		// the value of a constant can still be modified
+		{"const a = [1]\na[0] = 2",
+			nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			errs := testResolveProgram(tr, tt.input)
+
+			if tt.expectedErr == nil {
+				tr.AssertEqual(len(errs), 0, fmt.Sprint(errs))
+				return
+			}
+			tr.AssertEqual(len(errs), 1)
+			tr.AssertTrue(errors.Is(errs[0], tt.expectedErr), errs[0].Error())
+		})
+	}
+}
+
+func TestDuplicateBinding(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr error
+	}{
+		{"let [a, a] = xs",
+			IllegalDuplicateBindingError},
+		{"let {a, a} = m",
+			IllegalDuplicateBindingError},
+		{"each [k, k] : xs {}",
+			IllegalDuplicateBindingError},
+		{"let y = match xs {\n\t[x, x] => x\n}",
+			IllegalDuplicateBindingError},
+		{"fn f() {\n\tlet [a, ...a] = xs\n}",
+			IllegalDuplicateBindingError},
+
+		// shadowing a name in an inner scope is allowed
+		{"let [a, b] = xs\nfn f() {\n\tlet [a, b] = xs\n}",
+			nil},
+		{"each [k, v] : xs {\n\teach [k, v] : xs {}\n}",
+			nil},
+		{"let x = 1\nlet y = match xs {\n\t[x, y] => x\n}",
+			nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			errs := testResolveProgram(tr, tt.input)
+
+			if tt.expectedErr == nil {
+				tr.AssertEqual(len(errs), 0, fmt.Sprint(errs))
+				return
+			}
+			tr.AssertEqual(len(errs), 1, fmt.Sprint(errs))
+			tr.AssertTrue(errors.Is(errs[0], tt.expectedErr), errs[0].Error())
+		})
+	}
+}
+
+func TestPropagateOutsideFunction(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr error
+	}{
+		{"let x = f()?",
+			IllegalPropagateOutsideFunctionError},
+		{"each x : xs {\n\tx?\n}",
+			IllegalPropagateOutsideFunctionError},
+		{"fn f(x) {\n\treturn x?\n}",
+			nil},
+		{"let f = x => x?",
+			nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			errs := testResolveProgram(tr, tt.input)
+
+			if tt.expectedErr == nil {
+				tr.AssertEqual(len(errs), 0, fmt.Sprint(errs))
+				return
+			}
+			tr.AssertEqual(len(errs), 1)
+			tr.AssertTrue(errors.Is(errs[0], tt.expectedErr), errs[0].Error())
+		})
+	}
+}
+
+func TestDeferOutsideFunction(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr error
+	}{
+		{"defer close()",
+			IllegalDeferOutsideFunctionError},
+		{"while true {\n\tdefer close()\n}",
+			IllegalDeferOutsideFunctionError},
+		{"fn f() {\n\tdefer close()\n}",
+			nil},
+		{"fn f() {\n\teach x : xs {\n\t\tdefer close(x)\n\t}\n}",
+			nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			errs := testResolveProgram(tr, tt.input)
+
+			if tt.expectedErr == nil {
+				tr.AssertEqual(len(errs), 0, fmt.Sprint(errs))
+				return
+			}
+			tr.AssertEqual(len(errs), 1)
+			tr.AssertTrue(errors.Is(errs[0], tt.expectedErr), errs[0].Error())
+		})
+	}
+}
+
+func TestYieldOutsideFunction(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr error
+	}{
+		{"yield 1",
+			IllegalYieldOutsideFunctionError},
+		{"each x : xs {\n\tyield x\n}",
+			IllegalYieldOutsideFunctionError},
+		{"fn f() {\n\tyield 1\n}",
+			nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			errs := testResolveProgram(tr, tt.input)
+
+			if tt.expectedErr == nil {
+				tr.AssertEqual(len(errs), 0, fmt.Sprint(errs))
+				return
+			}
+			tr.AssertEqual(len(errs), 1)
+			tr.AssertTrue(errors.Is(errs[0], tt.expectedErr), errs[0].Error())
+		})
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"fn f(n) {\n\treturn f(n - 1)\n}", true},
+		{"let f = n => g(n)", true},
+		{"fn f(n) {\n\treturn 1 + f(n - 1)\n}", false},
+		{"fn f(n) {\n\tdefer close()\n\treturn f(n - 1)\n}", false},
+		{"fn f(n) {\n\tyield n\n\treturn f(n - 1)\n}", false},
+		{"fn f(n) {\n\ttry {\n\t\treturn f(n - 1)\n\t} catch {}\n}", false},
+		// a try statement in a nested function does not affect the outer function
+		{"fn f(n) {\n\tlet h = fn() {\n\t\ttry {} catch {}\n\t}\n\treturn f(n - 1)\n}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			if p.DidError() {
+				t.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+			}
+
+			r := New(object.NewEnv(nil))
+			r.Resolve(program)
+			tr.AssertEqual(len(r.Errors), 0, fmt.Sprint(r.Errors))
+
+			fun := program.Statements[0].(*ast.LetStmt).Value.(*ast.FunctionLiteralExpr)
+			call := returnedCall(tr, fun.Body.Statements)
+			tr.AssertEqual(call.Tail, tt.expected)
+		})
+	}
+}
+
+// finds the call returned by the last statement, or the last statement of a try body
+func returnedCall(tr *tester.Tester, stmts []ast.Stmt) *ast.CallExpr {
+	tr.T.Helper()
+
+	switch n := stmts[len(stmts)-1].(type) {
+	case *ast.TryStmt:
+		return returnedCall(tr, n.Body.Statements)
+	case *ast.ReturnStmt:
+		if call, ok := n.Value.(*ast.CallExpr); ok {
+			return call
+		}
+		if binary, ok := n.Value.(*ast.BinaryExpr); ok {
+			return binary.Right.(*ast.CallExpr)
+		}
+	}
+	tr.T.Fatalf("no returned call in %v", stmts)
+	return nil
+}
+
+func testResolveProgram(tr *tester.Tester, input string) []error {
+	tr.T.Helper()
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if p.DidError() {
+		tr.T.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+	}
+
+	r := New(object.NewEnv(nil))
+	r.Resolve(program)
+
+	return r.Errors
+}
@@ -2,7 +2,6 @@ package fmt_std
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/fredrikkvalvik/temp-lang/pkg/object"
@@ -16,13 +15,13 @@ var Module = object.ModuleObj{
 var vars = map[string]object.Object{
 	"println": &object.BuiltinObj{
 		Name: "println",
-		Fn: func(args ...object.Object) object.Object {
+		ContextFn: func(ctx object.Context, args ...object.Object) object.Object {
 			var str strings.Builder
 
 			str.WriteString(objectsToString(args...))
 			str.WriteString("\n")
 
-			fmt.Fprint(os.Stdout, str.String())
+			fmt.Fprint(ctx.Out(), str.String())
 			return nil
 		},
 	},
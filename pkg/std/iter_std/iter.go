@@ -0,0 +1,366 @@
+package iter_std
+
+import (
+	"fmt"
+
+	"github.com/fredrikkvalvik/temp-lang/pkg/object"
+)
+
+var Module = object.ModuleObj{
+	Name:       "iter",
+	ModuleType: object.NATIVE_MODULE,
+	Vars:       vars,
+}
+
+var vars = map[string]object.Object{
+	"map":        &object.BuiltinObj{Name: "map", ContextFn: mapFn},
+	"filter":     &object.BuiltinObj{Name: "filter", ContextFn: filterFn},
+	"take":       &object.BuiltinObj{Name: "take", ContextFn: takeFn},
+	"skip":       &object.BuiltinObj{Name: "skip", ContextFn: skipFn},
+	"zip":        &object.BuiltinObj{Name: "zip", ContextFn: zipFn},
+	"enumerate":  &object.BuiltinObj{Name: "enumerate", ContextFn: enumerateFn},
+	"chain":      &object.BuiltinObj{Name: "chain", ContextFn: chainFn},
+	"flat_map":   &object.BuiltinObj{Name: "flat_map", ContextFn: flatMapFn},
+	"take_while": &object.BuiltinObj{Name: "take_while", ContextFn: takeWhileFn},
+	"reduce":     &object.BuiltinObj{Name: "reduce", ContextFn: reduceFn},
+	"collect":    &object.BuiltinObj{Name: "collect", ContextFn: collectFn},
+	"count":      &object.BuiltinObj{Name: "count", ContextFn: countFn},
+}
+
+// Arity: 2
+//
+// Arg0: iterable, Arg1: function
+//
+// map returns an iterator of fn(item) for every item
+func mapFn(ctx object.Context, args ...object.Object) object.Object {
+	source, fn, err := iterableAndFunction(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	return newLazyIterator(func() (object.Object, bool) {
+		if source.Done() {
+			return nil, false
+		}
+		item := source.Next()
+		if isError(item) {
+			return item, true
+		}
+		return ctx.Call(fn, item), true
+	}, closing(source))
+}
+
+// Arity: 2
+//
+// Arg0: iterable, Arg1: function
+//
+// filter returns an iterator of the items where fn(item) is true
+func filterFn(ctx object.Context, args ...object.Object) object.Object {
+	source, fn, err := iterableAndFunction(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	return newLazyIterator(func() (object.Object, bool) {
+		for !source.Done() {
+			item := source.Next()
+			if isError(item) {
+				return item, true
+			}
+			keep, err := predicate(ctx, fn, item)
+			if err != nil {
+				return err, true
+			}
+			if keep {
+				return item, true
+			}
+		}
+		return nil, false
+	}, closing(source))
+}
+
+// Arity: 2
+//
+// Arg0: iterable, Arg1: number
+//
+// take returns an iterator of the first n items
+func takeFn(ctx object.Context, args ...object.Object) object.Object {
+	source, n, err := iterableAndCount(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	taken := 0
+	return newLazyIterator(func() (object.Object, bool) {
+		if taken >= n || source.Done() {
+			return nil, false
+		}
+		taken++
+		return source.Next(), true
+	}, closing(source))
+}
+
+// Arity: 2
+//
+// Arg0: iterable, Arg1: number
+//
+// skip returns an iterator without the first n items
+func skipFn(ctx object.Context, args ...object.Object) object.Object {
+	source, n, err := iterableAndCount(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	return newLazyIterator(func() (object.Object, bool) {
+		for ; n > 0 && !source.Done(); n-- {
+			if item := source.Next(); isError(item) {
+				return item, true
+			}
+		}
+		if source.Done() {
+			return nil, false
+		}
+		return source.Next(), true
+	}, closing(source))
+}
+
+// Arity: >1
+//
+// Arg0..n: iterable
+//
+// zip returns an iterator of lists with one item from each iterable.
+// It ends when the shortest iterable ends
+func zipFn(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) < 2 {
+		return &object.ErrorObj{Error: fmt.Errorf("%w: expected at least 2 args, got %d", object.ArityError, len(args))}
+	}
+	sources, err := iterators(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	return newLazyIterator(func() (object.Object, bool) {
+		items := make([]object.Object, 0, len(sources))
+		for _, source := range sources {
+			if source.Done() {
+				return nil, false
+			}
+			item := source.Next()
+			if isError(item) {
+				return item, true
+			}
+			items = append(items, item)
+		}
+		return &object.ListObj{Values: items}, true
+	}, closing(sources...))
+}
+
+// Arity: 1
+//
+// Arg0: iterable
+//
+// enumerate returns an iterator of [index, item] lists
+func enumerateFn(ctx object.Context, args ...object.Object) object.Object {
+	if err := object.CheckArity(args, 1); err != nil {
+		return err
+	}
+	source, err := object.NewIterator(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	index := 0
+	return newLazyIterator(func() (object.Object, bool) {
+		if source.Done() {
+			return nil, false
+		}
+		item := source.Next()
+		if isError(item) {
+			return item, true
+		}
+		pair := &object.ListObj{Values: []object.Object{&object.NumberObj{Value: float64(index)}, item}}
+		index++
+		return pair, true
+	}, closing(source))
+}
+
+// Arity: >0
+//
+// Arg0..n: iterable
+//
+// chain returns an iterator of the items of every iterable, one after the other
+func chainFn(ctx object.Context, args ...object.Object) object.Object {
+	sources, err := iterators(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	return newLazyIterator(func() (object.Object, bool) {
+		for len(sources) > 0 {
+			if !sources[0].Done() {
+				return sources[0].Next(), true
+			}
+			sources = sources[1:]
+		}
+		return nil, false
+	}, closing(sources...))
+}
+
+// Arity: 2
+//
+// Arg0: iterable, Arg1: function
+//
+// flat_map calls fn for every item, and returns an iterator of the items of
+// the iterables returned by fn
+func flatMapFn(ctx object.Context, args ...object.Object) object.Object {
+	source, fn, err := iterableAndFunction(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	var inner object.Iterator
+	return newLazyIterator(func() (object.Object, bool) {
+		for inner == nil || inner.Done() {
+			if source.Done() {
+				return nil, false
+			}
+			item := source.Next()
+			if isError(item) {
+				return item, true
+			}
+			mapped := ctx.Call(fn, item)
+			if isError(mapped) {
+				return mapped, true
+			}
+			var err *object.ErrorObj
+			inner, err = object.NewIterator(ctx, mapped)
+			if err != nil {
+				return err, true
+			}
+		}
+		return inner.Next(), true
+	}, func() {
+		object.CloseIterator(source)
+		if inner != nil {
+			object.CloseIterator(inner)
+		}
+	})
+}
+
+// Arity: 2
+//
+// Arg0: iterable, Arg1: function
+//
+// take_while returns an iterator of the items until fn(item) is false
+func takeWhileFn(ctx object.Context, args ...object.Object) object.Object {
+	source, fn, err := iterableAndFunction(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	return newLazyIterator(func() (object.Object, bool) {
+		if source.Done() {
+			return nil, false
+		}
+		item := source.Next()
+		if isError(item) {
+			return item, true
+		}
+		keep, err := predicate(ctx, fn, item)
+		if err != nil {
+			return err, true
+		}
+		if !keep {
+			return nil, false
+		}
+		return item, true
+	}, closing(source))
+}
+
+// Arity: 2 | 3
+//
+// Arg0: iterable, Arg1: function, Arg2: any
+//
+// reduce calls fn(acc, item) for every item, and returns the last result.
+// acc starts as the third argument, or as the first item when it is omitted
+func reduceFn(ctx object.Context, args ...object.Object) object.Object {
+	if len(args) != 2 && len(args) != 3 {
+		return &object.ErrorObj{Error: fmt.Errorf("%w: expected 2 or 3 args, got %d", object.ArityError, len(args))}
+	}
+	source, fn, err := iterableAndFunction(ctx, args[:2])
+	if err != nil {
+		return err
+	}
+	defer object.CloseIterator(source)
+
+	var acc object.Object
+	if len(args) == 3 {
+		acc = args[2]
+	} else {
+		if source.Done() {
+			return &object.ErrorObj{Error: fmt.Errorf("%w: reduce of empty iterable without initial value", object.TypeError)}
+		}
+		acc = source.Next()
+	}
+
+	for !source.Done() && !isError(acc) {
+		item := source.Next()
+		if isError(item) {
+			return item
+		}
+		acc = ctx.Call(fn, acc, item)
+	}
+
+	return acc
+}
+
+// Arity: 1
+//
+// Arg0: iterable
+//
+// collect returns a list of every item
+func collectFn(ctx object.Context, args ...object.Object) object.Object {
+	if err := object.CheckArity(args, 1); err != nil {
+		return err
+	}
+	source, err := object.NewIterator(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	defer object.CloseIterator(source)
+
+	list := &object.ListObj{}
+	for !source.Done() {
+		item := source.Next()
+		if isError(item) {
+			return item
+		}
+		list.Values = append(list.Values, item)
+	}
+	return list
+}
+
+// Arity: 1
+//
+// Arg0: iterable
+//
+// count returns the number of items
+func countFn(ctx object.Context, args ...object.Object) object.Object {
+	if err := object.CheckArity(args, 1); err != nil {
+		return err
+	}
+	source, err := object.NewIterator(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	defer object.CloseIterator(source)
+
+	count := 0
+	for !source.Done() {
+		if item := source.Next(); isError(item) {
+			return item
+		}
+		count++
+	}
+	return &object.NumberObj{Value: float64(count)}
+}
@@ -0,0 +1,106 @@
+package iter_std_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fredrikkvalvik/temp-lang/pkg/evaluator"
+	"github.com/fredrikkvalvik/temp-lang/pkg/lexer"
+	"github.com/fredrikkvalvik/temp-lang/pkg/object"
+	"github.com/fredrikkvalvik/temp-lang/pkg/parser"
+	"github.com/fredrikkvalvik/temp-lang/pkg/resolver"
+	"github.com/fredrikkvalvik/temp-lang/pkg/tester"
+)
+
+func TestIterModule(t *testing.T) {
+	// a generator that logs when its body has finished
+	gen := "let log = []\nlet gen = fn() { defer push(log, \"done\")\nyield 1\nyield 2 }\n"
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"iter.collect(iter.map([1, 2, 3], x => x * 2))", "[2, 4, 6]"},
+		{"iter.collect(iter.filter(0..6, x => x > 3))", "[4, 5]"},
+		{"iter.collect(iter.take(0..100, 3))", "[0, 1, 2]"},
+		{"iter.collect(iter.take([1], 3))", "[1]"},
+		{"iter.collect(iter.skip(0..5, 3))", "[3, 4]"},
+		{"iter.collect(iter.skip([1], 3))", "[]"},
+		{`iter.collect(iter.zip([1, 2, 3], "ab"))`, `[[1, "a"], [2, "b"]]`},
+		{`iter.collect(iter.enumerate("ab"))`, `[[0, "a"], [1, "b"]]`},
+		{"iter.collect(iter.chain([1], [], [2, 3]))", "[1, 2, 3]"},
+		{"iter.collect(iter.flat_map([1, 2, 3], x => 0..x))", "[0, 0, 1, 0, 1, 2]"},
+		{"iter.collect(iter.take_while([1, 2, 5, 1], x => x < 3))", "[1, 2]"},
+		{"iter.reduce([1, 2, 3], (acc, x) => acc + x, 10)", "16"},
+		{"iter.reduce([1, 2, 3], (acc, x) => acc * x)", "6"},
+		{"iter.count(iter.filter(0..10, x => x > 6))", "3"},
+		{"iter.count([])", "0"},
+		// nothing is pulled from the source before it is needed
+		{"let n = 0\nlet xs = iter.map(0..3, fn(x) { n = n + 1\nreturn x })\nn", "0"},
+		{"let n = 0\nlet xs = iter.map(0..3, fn(x) { n = n + 1\nreturn x })\niter.take(xs, 2) |> iter.collect()\nn", "2"},
+		// a generator is closed when the iterator pulling from it ends first, so its defers run
+		{gen + "iter.take(gen(), 1) |> iter.collect()\nlog", `["done"]`},
+		{gen + "iter.map(gen(), x => x * 2) |> iter.take(1) |> iter.collect()\nlog", `["done"]`},
+		{gen + "iter.flat_map([1], x => gen()) |> iter.take(1) |> iter.collect()\nlog", `["done"]`},
+		{gen + "iter.zip(gen(), [1]) |> iter.collect()\nlog", `["done"]`},
+		{gen + "let xs = iter.map(gen(), x => x)\nxs.next()\nxs.close()\nlog", `["done"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res := testRunProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Inspect(), tt.expected)
+		})
+	}
+}
+
+func TestIterModuleErrors(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr error
+	}{
+		{"iter.map([1])", object.ArityError},
+		{"iter.map([1], 1)", object.TypeError},
+		{"iter.take([1], 1.5)", object.TypeError},
+		{"iter.zip([1])", object.ArityError},
+		{"iter.collect(iter.filter([1], x => 1))", object.TypeError},
+		{"iter.reduce([], (acc, x) => acc)", object.TypeError},
+		{"iter.collect(iter.map([1], x => [][x]))", evaluator.IndexOutOfBoundsError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tr := tester.New(t, "")
+
+			res := testRunProgram(tr, tt.input)
+
+			tr.AssertNotNil(res)
+			tr.AssertEqual(res.Type(), object.OBJ_ERROR, res.Inspect())
+			tr.AssertTrue(errors.Is(res.(*object.ErrorObj).Error, tt.expectedErr), res.Inspect())
+		})
+	}
+}
+
+func testRunProgram(tr *tester.Tester, input string) object.Object {
+	tr.T.Helper()
+
+	l := lexer.New("import iter \"iter\";\n" + input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if p.DidError() {
+		tr.T.Fatalf("parser error\n%s", errors.Join(p.Errors()...))
+	}
+
+	env := object.NewEnv(nil)
+	r := resolver.New(env)
+	r.Resolve(program)
+	if len(r.Errors) > 0 {
+		tr.T.Fatalf("resolver error\n%s", errors.Join(r.Errors...))
+	}
+
+	return evaluator.Eval(program, env)
+}
@@ -0,0 +1,134 @@
+package iter_std
+
+import (
+	"fmt"
+
+	"github.com/fredrikkvalvik/temp-lang/pkg/object"
+)
+
+// lazyIter adapts a pull function to object.Iterator. pull returns false when
+// there are no more items. The next item is pulled ahead, so Done can tell
+// if there is one, and nothing is pulled before it is needed.
+// close is called once, when the iterator is done or closed, to close the
+// iterators it pulls from, since it can end before they do
+type lazyIter struct {
+	pull  func() (object.Object, bool)
+	close func()
+
+	value    object.Object
+	buffered bool
+	done     bool
+}
+
+func newLazyIterator(pull func() (object.Object, bool), close func()) *object.IteratorObj {
+	return &object.IteratorObj{Iterator: &lazyIter{pull: pull, close: close}}
+}
+
+// returns a function that closes every source
+func closing(sources ...object.Iterator) func() {
+	return func() {
+		for _, source := range sources {
+			object.CloseIterator(source)
+		}
+	}
+}
+
+func (li *lazyIter) Type() object.IteratorType { return object.ITER_LAZY }
+
+func (li *lazyIter) Next() object.Object {
+	li.fill()
+	if li.done {
+		return nil
+	}
+	li.buffered = false
+	return li.value
+}
+
+func (li *lazyIter) Done() bool {
+	li.fill()
+	return li.done
+}
+
+func (li *lazyIter) fill() {
+	if li.buffered || li.done {
+		return
+	}
+
+	value, ok := li.pull()
+	if !ok {
+		li.Close()
+		return
+	}
+	li.value = value
+	li.buffered = true
+}
+
+func (li *lazyIter) Close() {
+	li.done = true
+	li.buffered = false
+	li.value = nil
+	if li.close != nil {
+		li.close()
+		li.close = nil
+	}
+}
+
+func isError(obj object.Object) bool {
+	return obj != nil && obj.Type() == object.OBJ_ERROR
+}
+
+// calls fn with item, and returns an error if the result is not a boolean
+func predicate(ctx object.Context, fn, item object.Object) (bool, object.Object) {
+	res := ctx.Call(fn, item)
+	if isError(res) {
+		return false, res
+	}
+	b, ok := res.(*object.BooleanObj)
+	if !ok {
+		return false, &object.ErrorObj{Error: fmt.Errorf("%w: expected predicate to return boolean, got %s", object.TypeError, res.Type())}
+	}
+	return b.Value, nil
+}
+
+func iterators(ctx object.Context, args []object.Object) ([]object.Iterator, *object.ErrorObj) {
+	sources := make([]object.Iterator, 0, len(args))
+	for _, arg := range args {
+		source, err := object.NewIterator(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func iterableAndFunction(ctx object.Context, args []object.Object) (object.Iterator, object.Object, *object.ErrorObj) {
+	if err := object.CheckArity(args, 2); err != nil {
+		return nil, nil, err
+	}
+	if err := object.CheckCallable(args[1]); err != nil {
+		return nil, nil, err
+	}
+	source, err := object.NewIterator(ctx, args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, args[1], nil
+}
+
+func iterableAndCount(ctx object.Context, args []object.Object) (object.Iterator, int, *object.ErrorObj) {
+	var ebuf object.ErrorBuf[object.ErrorObj]
+
+	ebuf.Run(func() *object.ErrorObj { return object.CheckArity(args, 2) })
+	ebuf.Run(func() *object.ErrorObj { return object.CheckObjectType(args[1], object.OBJ_NUMBER) })
+	ebuf.Run(func() *object.ErrorObj { return object.CheckIntegral(args[1].(*object.NumberObj).Value) })
+	if ebuf.Err != nil {
+		return nil, 0, ebuf.Err
+	}
+
+	source, err := object.NewIterator(ctx, args[0])
+	if err != nil {
+		return nil, 0, err
+	}
+	return source, int(args[1].(*object.NumberObj).Value), nil
+}
@@ -0,0 +1,79 @@
+package token
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// Source is a file the lexer reads from, with a table of where each line
+// starts. Every Pos of the tokens lexed from the file points to the same Source
+type Source struct {
+	// the path of the file, or a name like "<repl>". Empty for text that is
+	// not from a file
+	Path string
+	Text string
+	// byte offset of the first character of each line
+	lines []int
+}
+
+// NewSource builds the line table of text
+func NewSource(path, text string) *Source {
+	lines := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return &Source{Path: path, Text: text, lines: lines}
+}
+
+// FileSet is the registry of the source files that are read, by path
+type FileSet struct {
+	files map[string]*Source
+}
+
+func NewFileSet() *FileSet {
+	return &FileSet{files: map[string]*Source{}}
+}
+
+// Add registers the contents of the file at path, and replaces any earlier contents
+func (fs *FileSet) Add(path, text string) *Source {
+	src := NewSource(path, text)
+	fs.files[path] = src
+	return src
+}
+
+// File returns the registered file at path, or nil
+func (fs *FileSet) File(path string) *Source {
+	return fs.files[path]
+}
+
+// Position returns the line and column of the byte offset. Both start at 1,
+// and the column counts runes
+func (s *Source) Position(offset int) (int, int) {
+	offset = max(0, min(offset, len(s.Text)))
+
+	// the first line that starts after offset is the line after it
+	line := sort.Search(len(s.lines), func(i int) bool { return s.lines[i] > offset })
+	col := utf8.RuneCountInString(s.Text[s.lines[line-1]:offset]) + 1
+
+	return line, col
+}
+
+// Line returns the text of the line, without the newline
+func (s *Source) Line(line int) string {
+	if line < 1 || line > len(s.lines) {
+		return ""
+	}
+	start := s.lines[line-1]
+	end := len(s.Text)
+	if line < len(s.lines) {
+		end = s.lines[line] - 1
+	}
+	return s.Text[start:end]
+}
+
+// LineStart returns the byte offset the line starts at
+func (s *Source) LineStart(line int) int {
+	return s.lines[max(1, min(line, len(s.lines)))-1]
+}
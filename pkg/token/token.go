@@ -28,6 +28,10 @@ const (
 
 	LT
 	GT
+	ARROW
+	PIPE
+	QUESTION // the ternary `c ? a : b`, or the postfix `value?` that returns early when value is an error
+	NULLISH  // ??
 
 	AND
 	OR
@@ -35,6 +39,10 @@ const (
 	// Delimiters
 	COMMA
 	DOT
+	OPTIONAL_DOT // ?.
+	RANGE        // ..
+	RANGE_INCL   // ..=
+	ELLIPSIS
 	SEMICOLON
 	COLON
 
@@ -43,6 +51,7 @@ const (
 	LBRACE
 	RBRACE
 	LBRACKET
+	OPTIONAL_LBRACKET // ?[
 	RBRACKET
 
 	// Keywords
@@ -51,28 +60,44 @@ const (
 	EACH
 	WHILE
 	LET
+	CONST
 	TRUE
 	FALSE
 	IF
 	ELSE
 	RETURN
 	PRINT
+	MATCH
+	TRY
+	CATCH
+	FINALLY
+	THROW
+	DEFER
+	YIELD
 )
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"import": IMPORT,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"and":    AND,
-	"or":     OR,
-	"each":   EACH,
-	"while":  WHILE,
-	"print":  PRINT,
+	"fn":      FUNCTION,
+	"import":  IMPORT,
+	"let":     LET,
+	"const":   CONST,
+	"true":    TRUE,
+	"false":   FALSE,
+	"if":      IF,
+	"else":    ELSE,
+	"return":  RETURN,
+	"and":     AND,
+	"or":      OR,
+	"each":    EACH,
+	"while":   WHILE,
+	"print":   PRINT,
+	"match":   MATCH,
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+	"throw":   THROW,
+	"defer":   DEFER,
+	"yield":   YIELD,
 }
 
 func LookupIdent(ident string) TokenType {
@@ -84,36 +109,34 @@ func LookupIdent(ident string) TokenType {
 }
 
 type Pos struct {
-	Src        *string
-	Start, End int
+	Src        *Source
+	Start, End int // byte offsets of the lexeme in Src
 }
 
 // returns the line:column pair for the token
 func (p *Pos) Position() (int, int) {
-	col := 1
-	line := 1
-
-	position := 0
-	// increment col for each loop.
-	// when we see a '\n', reset col and keep moving
-	// return col at end
-	for position < p.Start {
-		if (*p.Src)[position] == '\n' {
-			line += 1
-			col = 1
-		} else {
-			col += 1
-		}
-
-		position += 1
+	if p.Src == nil {
+		return 1, 1
 	}
-
-	return line, col
+	return p.Src.Position(p.Start)
 }
 
+// returns path:line:col, or [line:col] when the source is not a file
 func (p Pos) String() string {
+	if p.Src == nil || p.Src.Path == "" {
+		line, col := p.Position()
+		return fmt.Sprintf("[%d:%d]", line, col)
+	}
+	return p.Location()
+}
+
+// returns path:line:col, or line:col when the source is not a file
+func (p Pos) Location() string {
 	line, col := p.Position()
-	return fmt.Sprintf("[%d:%d]", line, col)
+	if p.Src == nil || p.Src.Path == "" {
+		return fmt.Sprintf("%d:%d", line, col)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Src.Path, line, col)
 }
 
 type Token struct {
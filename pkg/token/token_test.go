@@ -16,3 +16,83 @@ func TestTokenPrint(t *testing.T) {
 		fmt.Println(tok)
 	}
 }
+
+func TestSourcePosition(t *testing.T) {
+	src := token.NewSource("", "let a = 1\n\nlet é = \"ø\" + a\n")
+
+	tests := []struct {
+		offset       int
+		expectedLine int
+		expectedCol  int
+	}{
+		{0, 1, 1},
+		{4, 1, 5},
+		{9, 1, 10}, // the newline belongs to the line it ends
+		{10, 2, 1}, // empty line
+		{11, 3, 1},
+		{18, 3, 7},  // `=`, after the two byte `é`
+		{23, 3, 11}, // the closing `"`, after the two byte `ø`
+		{29, 4, 1},  // end of the source
+		{100, 4, 1}, // offsets past the end are clamped
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.offset), func(t *testing.T) {
+			line, col := src.Position(tt.offset)
+			if line != tt.expectedLine || col != tt.expectedCol {
+				t.Errorf("expected=%d:%d, got=%d:%d", tt.expectedLine, tt.expectedCol, line, col)
+			}
+		})
+	}
+
+	if line := src.Line(3); line != "let é = \"ø\" + a" {
+		t.Errorf("expected line 3, got=%q", line)
+	}
+}
+
+func TestPosString(t *testing.T) {
+	file := token.NewSource("examples/main.tln", "let a = 1\nlet b = a")
+	text := token.NewSource("", "let a = 1\nlet b = a")
+
+	tests := []struct {
+		pos              token.Pos
+		expectedString   string
+		expectedLocation string
+	}{
+		{token.Pos{Src: file, Start: 18, End: 19}, "examples/main.tln:2:9", "examples/main.tln:2:9"},
+		{token.Pos{Src: text, Start: 18, End: 19}, "[2:9]", "2:9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expectedString, func(t *testing.T) {
+			if tt.pos.String() != tt.expectedString {
+				t.Errorf("expected=%s, got=%s", tt.expectedString, tt.pos.String())
+			}
+			if tt.pos.Location() != tt.expectedLocation {
+				t.Errorf("expected=%s, got=%s", tt.expectedLocation, tt.pos.Location())
+			}
+		})
+	}
+}
+
+func TestFileSet(t *testing.T) {
+	files := token.NewFileSet()
+	main := files.Add("examples/main.tln", "import \"lib.tln\"")
+	lib := files.Add("examples/lib.tln", "let a = 1")
+
+	if files.File("examples/main.tln") != main {
+		t.Errorf("expected examples/main.tln to be registered")
+	}
+	if files.File("examples/lib.tln") != lib {
+		t.Errorf("expected examples/lib.tln to be registered")
+	}
+	if files.File("examples/other.tln") != nil {
+		t.Errorf("expected no file at examples/other.tln")
+	}
+
+	// adding a path again replaces its contents
+	again := files.Add("examples/lib.tln", "let b = 2")
+	if files.File("examples/lib.tln") != again || again.Text != "let b = 2" {
+		t.Errorf("expected examples/lib.tln to be replaced")
+	}
+}
@@ -23,34 +23,51 @@ func _() {
 	_ = x[NOT_EQ-12]
 	_ = x[LT-13]
 	_ = x[GT-14]
-	_ = x[AND-15]
-	_ = x[OR-16]
-	_ = x[COMMA-17]
-	_ = x[DOT-18]
-	_ = x[SEMICOLON-19]
-	_ = x[COLON-20]
-	_ = x[LPAREN-21]
-	_ = x[RPAREN-22]
-	_ = x[LBRACE-23]
-	_ = x[RBRACE-24]
-	_ = x[LBRACKET-25]
-	_ = x[RBRACKET-26]
-	_ = x[FUNCTION-27]
-	_ = x[IMPORT-28]
-	_ = x[EACH-29]
-	_ = x[WHILE-30]
-	_ = x[LET-31]
-	_ = x[TRUE-32]
-	_ = x[FALSE-33]
-	_ = x[IF-34]
-	_ = x[ELSE-35]
-	_ = x[RETURN-36]
-	_ = x[PRINT-37]
+	_ = x[ARROW-15]
+	_ = x[PIPE-16]
+	_ = x[QUESTION-17]
+	_ = x[NULLISH-18]
+	_ = x[AND-19]
+	_ = x[OR-20]
+	_ = x[COMMA-21]
+	_ = x[DOT-22]
+	_ = x[OPTIONAL_DOT-23]
+	_ = x[RANGE-24]
+	_ = x[RANGE_INCL-25]
+	_ = x[ELLIPSIS-26]
+	_ = x[SEMICOLON-27]
+	_ = x[COLON-28]
+	_ = x[LPAREN-29]
+	_ = x[RPAREN-30]
+	_ = x[LBRACE-31]
+	_ = x[RBRACE-32]
+	_ = x[LBRACKET-33]
+	_ = x[OPTIONAL_LBRACKET-34]
+	_ = x[RBRACKET-35]
+	_ = x[FUNCTION-36]
+	_ = x[IMPORT-37]
+	_ = x[EACH-38]
+	_ = x[WHILE-39]
+	_ = x[LET-40]
+	_ = x[CONST-41]
+	_ = x[TRUE-42]
+	_ = x[FALSE-43]
+	_ = x[IF-44]
+	_ = x[ELSE-45]
+	_ = x[RETURN-46]
+	_ = x[PRINT-47]
+	_ = x[MATCH-48]
+	_ = x[TRY-49]
+	_ = x[CATCH-50]
+	_ = x[FINALLY-51]
+	_ = x[THROW-52]
+	_ = x[DEFER-53]
+	_ = x[YIELD-54]
 }
 
-const _TokenType_name = "ILLEGALEOFIDENTNUMBERSTRINGASSIGNPLUSMINUSBANGASTERISKSLASHEQNOT_EQLTGTANDORCOMMADOTSEMICOLONCOLONLPARENRPARENLBRACERBRACELBRACKETRBRACKETFUNCTIONIMPORTEACHWHILELETTRUEFALSEIFELSERETURNPRINT"
+const _TokenType_name = "ILLEGALEOFIDENTNUMBERSTRINGASSIGNPLUSMINUSBANGASTERISKSLASHEQNOT_EQLTGTARROWPIPEQUESTIONNULLISHANDORCOMMADOTOPTIONAL_DOTRANGERANGE_INCLELLIPSISSEMICOLONCOLONLPARENRPARENLBRACERBRACELBRACKETOPTIONAL_LBRACKETRBRACKETFUNCTIONIMPORTEACHWHILELETCONSTTRUEFALSEIFELSERETURNPRINTMATCHTRYCATCHFINALLYTHROWDEFERYIELD"
 
-var _TokenType_index = [...]uint8{0, 7, 10, 15, 21, 27, 33, 37, 42, 46, 54, 59, 61, 67, 69, 71, 74, 76, 81, 84, 93, 98, 104, 110, 116, 122, 130, 138, 146, 152, 156, 161, 164, 168, 173, 175, 179, 185, 190}
+var _TokenType_index = [...]uint16{0, 7, 10, 15, 21, 27, 33, 37, 42, 46, 54, 59, 61, 67, 69, 71, 76, 80, 88, 95, 98, 100, 105, 108, 120, 125, 135, 143, 152, 157, 163, 169, 175, 181, 189, 206, 214, 222, 228, 232, 237, 240, 245, 249, 254, 256, 260, 266, 271, 276, 279, 284, 291, 296, 301, 306}
 
 func (i TokenType) String() string {
 	if i < 0 || i >= TokenType(len(_TokenType_index)-1) {